@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// PrinterColumnWarnings returns a warning for each user-defined printer column
+// of every version of the supplied CRD that shadows a printer column Crossplane
+// injects. A user-defined column shadows an injected column if it shows the
+// same field, or if its name differs from that of the injected column only by
+// case; kubectl prints column names in upper case. Shadowing columns are
+// confusing, particularly when they have a different priority than the
+// injected column and thus appear only in wide output. Warnings are sorted and
+// prefixed with the path of the column, rooted at the name of the supplied CRD.
+func PrinterColumnWarnings(crd *extv1.CustomResourceDefinition) []string {
+	injected := map[string]bool{}
+	for _, cols := range [][]extv1.CustomResourceColumnDefinition{CompositeResourcePrinterColumns(), CompositeResourceClaimPrinterColumns(), TerminatingPrinterColumns()} {
+		for _, c := range cols {
+			injected[c.Name] = true
+		}
+	}
+
+	var warnings []string
+	p := field.NewPath("crds").Key(crd.GetName()).Child("spec", "versions")
+	for i, v := range crd.Spec.Versions {
+		for j, c := range v.AdditionalPrinterColumns {
+			if injected[c.Name] {
+				continue
+			}
+			if shadowed, ok := shadowedColumn(c, v.AdditionalPrinterColumns, injected); ok {
+				warnings = append(warnings, fmt.Sprintf("%s: column %q shadows column %q, which Crossplane injects", p.Index(i).Child("additionalPrinterColumns").Index(j), c.Name, shadowed))
+			}
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// shadowedColumn returns the name of the injected column among the supplied
+// columns that the supplied column shadows, if any.
+func shadowedColumn(c extv1.CustomResourceColumnDefinition, cols []extv1.CustomResourceColumnDefinition, injected map[string]bool) (string, bool) {
+	for _, ic := range cols {
+		if !injected[ic.Name] {
+			continue
+		}
+		if ic.JSONPath == c.JSONPath || strings.EqualFold(ic.Name, c.Name) {
+			return ic.Name, true
+		}
+	}
+	return "", false
+}
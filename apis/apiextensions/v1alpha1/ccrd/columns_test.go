@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestPrinterColumnWarnings(t *testing.T) {
+	prefix := "crds[coolcomposites.example.org].spec.versions[0].additionalPrinterColumns"
+
+	cases := map[string]struct {
+		reason string
+		cols   []extv1.CustomResourceColumnDefinition
+		want   []string
+	}{
+		"NoUserColumns": {
+			reason: "The injected printer columns should not produce warnings.",
+		},
+		"DistinctColumns": {
+			reason: "User-defined columns that show different fields with different names should not produce warnings.",
+			cols: []extv1.CustomResourceColumnDefinition{
+				{Name: "REGION", Type: "string", JSONPath: ".spec.region"},
+				{Name: "ENGINE", Type: "string", JSONPath: ".spec.engine", Priority: 1},
+			},
+		},
+		"ShadowingColumns": {
+			reason: "User-defined columns that show the same field as, or have a similar name to, an injected column should produce warnings.",
+			cols: []extv1.CustomResourceColumnDefinition{
+				{Name: "REGION", Type: "string", JSONPath: ".spec.region"},
+				{Name: "SYNCED", Type: "string", JSONPath: ".status.conditions[?(@.type=='Ready')].status", Priority: 1},
+				{Name: "Composition", Type: "string", JSONPath: ".spec.composition"},
+			},
+			want: []string{
+				prefix + `[1]: column "SYNCED" shadows column "READY", which Crossplane injects`,
+				prefix + `[2]: column "Composition" shadows column "COMPOSITION", which Crossplane injects`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+			d.Spec.Versions[0].AdditionalPrinterColumns = tc.cols
+			crd, err := ForCompositeResource(d)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			got := PrinterColumnWarnings(crd)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPrinterColumnWarnings(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"fmt"
+	"sort"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// BreakingSchemaChanges returns a description of each change between the
+// supplied old and new schemas that could break existing resources. Removing a
+// property, changing the type of a property, requiring a property that was not
+// previously required, and removing a value from (or adding) an enum are all
+// breaking changes. Adding an optional property is not. Descriptions are sorted
+// and prefixed with the path of the changed property, rooted at
+// openAPIV3Schema.
+func BreakingSchemaChanges(oldSchema, newSchema *extv1.JSONSchemaProps) []string {
+	if oldSchema == nil || newSchema == nil {
+		return nil
+	}
+	changes := breakingChanges(field.NewPath("openAPIV3Schema"), *oldSchema, *newSchema)
+	sort.Strings(changes)
+	return changes
+}
+
+func breakingChanges(p *field.Path, old, new extv1.JSONSchemaProps) []string {
+	if old.Type != new.Type {
+		return []string{fmt.Sprintf("%s: type changed from %q to %q", p, old.Type, new.Type)}
+	}
+
+	changes := append(newlyRequired(p, old, new), tightenedEnum(p, old, new)...)
+	for k, op := range old.Properties {
+		np, ok := new.Properties[k]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: property was removed", p.Child("properties").Key(k)))
+			continue
+		}
+		changes = append(changes, breakingChanges(p.Child("properties").Key(k), op, np)...)
+	}
+	changes = append(changes, nestedChanges(p.Child("items"), itemsSchema(old), itemsSchema(new))...)
+	changes = append(changes, nestedChanges(p.Child("additionalProperties"), additionalPropertiesSchema(old), additionalPropertiesSchema(new))...)
+	return changes
+}
+
+func nestedChanges(p *field.Path, old, new *extv1.JSONSchemaProps) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+	return breakingChanges(p, *old, *new)
+}
+
+func itemsSchema(s extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	if s.Items == nil {
+		return nil
+	}
+	return s.Items.Schema
+}
+
+func additionalPropertiesSchema(s extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	if s.AdditionalProperties == nil {
+		return nil
+	}
+	return s.AdditionalProperties.Schema
+}
+
+func newlyRequired(p *field.Path, old, new extv1.JSONSchemaProps) []string {
+	required := sets.NewString(old.Required...)
+	var changes []string
+	for _, r := range new.Required {
+		if !required.Has(r) {
+			changes = append(changes, fmt.Sprintf("%s: property is now required", p.Child("properties").Key(r)))
+		}
+	}
+	return changes
+}
+
+func tightenedEnum(p *field.Path, old, new extv1.JSONSchemaProps) []string {
+	if len(new.Enum) == 0 {
+		return nil
+	}
+	if len(old.Enum) == 0 {
+		return []string{fmt.Sprintf("%s: values are now restricted to an enum", p.Child("enum"))}
+	}
+
+	allowed := sets.NewString()
+	for _, v := range new.Enum {
+		allowed.Insert(string(v.Raw))
+	}
+	var changes []string
+	for _, v := range old.Enum {
+		if !allowed.Has(string(v.Raw)) {
+			changes = append(changes, fmt.Sprintf("%s: value %s was removed", p.Child("enum"), v.Raw))
+		}
+	}
+	return changes
+}
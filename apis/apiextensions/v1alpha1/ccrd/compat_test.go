@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestBreakingSchemaChanges(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		old    string
+		new    string
+		want   []string
+	}{
+		"Unchanged": {
+			reason: "Identical schemas should have no breaking changes.",
+			old:    `{"type":"object","properties":{"size":{"type":"integer"}}}`,
+			new:    `{"type":"object","properties":{"size":{"type":"integer"}}}`,
+		},
+		"AddedOptionalProperty": {
+			reason: "Adding an optional property, or adding a value to an enum, is not a breaking change.",
+			old:    `{"type":"object","properties":{"tier":{"type":"string","enum":["small","large"]}}}`,
+			new:    `{"type":"object","properties":{"tier":{"type":"string","enum":["small","medium","large"]},"zone":{"type":"string"}}}`,
+		},
+		"RemovedProperty": {
+			reason: "Removing a property, including a nested property, is a breaking change.",
+			old:    `{"type":"object","required":["size"],"properties":{"size":{"type":"integer"},"network":{"type":"object","properties":{"cidr":{"type":"string"}}}}}`,
+			new:    `{"type":"object","properties":{"network":{"type":"object"}}}`,
+			want: []string{
+				"openAPIV3Schema.properties[network].properties[cidr]: property was removed",
+				"openAPIV3Schema.properties[size]: property was removed",
+			},
+		},
+		"ChangedType": {
+			reason: "Changing the type of a property is a breaking change.",
+			old:    `{"type":"object","properties":{"zones":{"type":"array","items":{"type":"string"}}}}`,
+			new:    `{"type":"object","properties":{"zones":{"type":"array","items":{"type":"integer"}}}}`,
+			want:   []string{`openAPIV3Schema.properties[zones].items: type changed from "string" to "integer"`},
+		},
+		"NewlyRequired": {
+			reason: "Requiring a property that was previously optional is a breaking change.",
+			old:    `{"type":"object","properties":{"size":{"type":"integer"}}}`,
+			new:    `{"type":"object","required":["size"],"properties":{"size":{"type":"integer"}}}`,
+			want:   []string{"openAPIV3Schema.properties[size]: property is now required"},
+		},
+		"TightenedEnum": {
+			reason: "Removing a value from an enum, or newly restricting values to an enum, is a breaking change.",
+			old:    `{"type":"object","properties":{"tier":{"type":"string","enum":["small","medium","large"]},"region":{"type":"string"}}}`,
+			new:    `{"type":"object","properties":{"tier":{"type":"string","enum":["small","large"]},"region":{"type":"string","enum":["us-west-1"]}}}`,
+			want: []string{
+				"openAPIV3Schema.properties[region].enum: values are now restricted to an enum",
+				`openAPIV3Schema.properties[tier].enum: value "medium" was removed`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			old, new := &extv1.JSONSchemaProps{}, &extv1.JSONSchemaProps{}
+			if err := json.Unmarshal([]byte(tc.old), old); err != nil {
+				t.Fatalf("json.Unmarshal(...): %s", err)
+			}
+			if err := json.Unmarshal([]byte(tc.new), new); err != nil {
+				t.Fatalf("json.Unmarshal(...): %s", err)
+			}
+			got := BreakingSchemaChanges(old, new)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nBreakingSchemaChanges(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
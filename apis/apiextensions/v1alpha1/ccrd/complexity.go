@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Default schema complexity limits. These are conservative estimates of the
+// complexity api-server and its clients will tolerate, not hard limits.
+const (
+	DefaultMaxSchemaDepth      = 32
+	DefaultMaxSchemaProperties = 2048
+)
+
+// errDepthExceeded stops maxDepth walking a schema once it exceeds the limit.
+var errDepthExceeded = errors.New("schema depth limit exceeded")
+
+const (
+	errFmtSchemaTooDeep     = "schema of version %q is nested %d levels deep, exceeding the maximum of %d"
+	errFmtSchemaDeeperThan  = "schema of version %q is nested more than %d levels deep"
+	errFmtTooManyProperties = "schema of version %q has %d properties, exceeding the maximum of %d"
+)
+
+type complexity struct {
+	maxDepth      int
+	maxProperties int
+}
+
+// A ComplexityOption configures how the complexity of a CRD is checked.
+type ComplexityOption func(*complexity)
+
+// WithMaxSchemaDepth limits how deeply a CRD's schemas may be nested.
+func WithMaxSchemaDepth(n int) ComplexityOption {
+	return func(c *complexity) {
+		c.maxDepth = n
+	}
+}
+
+// WithMaxSchemaProperties limits the total number of properties each of a
+// CRD's schemas may contain, including nested properties.
+func WithMaxSchemaProperties(n int) ComplexityOption {
+	return func(c *complexity) {
+		c.maxProperties = n
+	}
+}
+
+// CheckComplexity returns an error if the schema of any version of the supplied
+// CRD is too complex, i.e. too deeply nested or containing too many properties.
+// Overly complex schemas may be rejected by api-server.
+func CheckComplexity(crd *extv1.CustomResourceDefinition, o ...ComplexityOption) error {
+	c := &complexity{maxDepth: DefaultMaxSchemaDepth, maxProperties: DefaultMaxSchemaProperties}
+	for _, fn := range o {
+		fn(c)
+	}
+
+	for _, vr := range crd.Spec.Versions {
+		if vr.Schema == nil || vr.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+
+		depth, props := 0, 0
+		_ = walkSchema(vr.Schema.OpenAPIV3Schema, nil, func(_ *field.Path, s *extv1.JSONSchemaProps, d int) error {
+			if d > depth {
+				depth = d
+			}
+			props += len(s.Properties)
+			return nil
+		})
+
+		if depth > c.maxDepth {
+			return errors.Errorf(errFmtSchemaTooDeep, vr.Name, depth, c.maxDepth)
+		}
+		if props > c.maxProperties {
+			return errors.Errorf(errFmtTooManyProperties, vr.Name, props, c.maxProperties)
+		}
+	}
+
+	return nil
+}
+
+// maxDepth returns how many levels deep the supplied schema is nested, up to
+// the supplied limit. A schema with no nested schemas is one level deep. The
+// schema is not walked more deeply than necessary to determine that it exceeds
+// the limit; limit+1 is returned for any schema that is nested more deeply.
+func maxDepth(s *extv1.JSONSchemaProps, limit int) int {
+	deepest := 0
+	_ = walkSchema(s, nil, func(_ *field.Path, _ *extv1.JSONSchemaProps, depth int) error {
+		if depth > deepest {
+			deepest = depth
+		}
+		if depth > limit {
+			return errDepthExceeded
+		}
+		return nil
+	})
+	return deepest
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// nested returns an object schema nested the supplied number of levels deep.
+func nested(depth int) *extv1.JSONSchemaProps {
+	s := &extv1.JSONSchemaProps{Type: "string"}
+	for i := 1; i < depth; i++ {
+		s = &extv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]extv1.JSONSchemaProps{"nested": *s},
+		}
+	}
+	return s
+}
+
+// wide returns an object schema with the supplied number of properties.
+func wide(props int) *extv1.JSONSchemaProps {
+	s := &extv1.JSONSchemaProps{Type: "object", Properties: map[string]extv1.JSONSchemaProps{}}
+	for i := 0; i < props; i++ {
+		s.Properties[fmt.Sprintf("p%d", i)] = extv1.JSONSchemaProps{Type: "string"}
+	}
+	return s
+}
+
+func TestCheckComplexity(t *testing.T) {
+	crd := func(s *extv1.JSONSchemaProps) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Versions: []extv1.CustomResourceDefinitionVersion{{
+					Name:   "v1alpha1",
+					Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: s},
+				}},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		crd    *extv1.CustomResourceDefinition
+		o      []ComplexityOption
+		want   error
+	}{
+		"NoSchema": {
+			reason: "A CRD without schemas is not complex.",
+			crd:    &extv1.CustomResourceDefinition{Spec: extv1.CustomResourceDefinitionSpec{Versions: []extv1.CustomResourceDefinitionVersion{{Name: "v1alpha1"}}}},
+		},
+		"WithinLimits": {
+			reason: "A schema that is exactly as deep as the limit should be accepted.",
+			crd:    crd(nested(4)),
+			o:      []ComplexityOption{WithMaxSchemaDepth(4)},
+		},
+		"TooDeep": {
+			reason: "A schema that is deeper than the configured limit should be rejected.",
+			crd:    crd(nested(5)),
+			o:      []ComplexityOption{WithMaxSchemaDepth(4)},
+			want:   errors.Errorf(errFmtSchemaTooDeep, "v1alpha1", 5, 4),
+		},
+		"TooDeepByDefault": {
+			reason: "A schema that is deeper than the default limit should be rejected.",
+			crd:    crd(nested(DefaultMaxSchemaDepth + 1)),
+			want:   errors.Errorf(errFmtSchemaTooDeep, "v1alpha1", DefaultMaxSchemaDepth+1, DefaultMaxSchemaDepth),
+		},
+		"TooDeepViaItems": {
+			reason: "Array items should count toward a schema's depth.",
+			crd: crd(&extv1.JSONSchemaProps{
+				Type:  "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: nested(4)},
+			}),
+			o:    []ComplexityOption{WithMaxSchemaDepth(4)},
+			want: errors.Errorf(errFmtSchemaTooDeep, "v1alpha1", 5, 4),
+		},
+		"TooManyProperties": {
+			reason: "A schema with more properties than the configured limit should be rejected.",
+			crd:    crd(wide(11)),
+			o:      []ComplexityOption{WithMaxSchemaProperties(10)},
+			want:   errors.Errorf(errFmtTooManyProperties, "v1alpha1", 11, 10),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := CheckComplexity(tc.crd, tc.o...)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCheckComplexity(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		s      *extv1.JSONSchemaProps
+		limit  int
+		want   int
+	}{
+		"Flat": {
+			reason: "A schema with no nested schemas should be one level deep.",
+			s:      &extv1.JSONSchemaProps{Type: "string"},
+			limit:  DefaultMaxSchemaDepth,
+			want:   1,
+		},
+		"Nested": {
+			reason: "Each level of nested properties should count toward the depth.",
+			s:      nested(7),
+			limit:  DefaultMaxSchemaDepth,
+			want:   7,
+		},
+		"Deepest": {
+			reason: "The depth of the most deeply nested schema should be returned.",
+			s: &extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"shallow": *nested(2),
+					"deep":    *nested(5),
+				},
+			},
+			limit: DefaultMaxSchemaDepth,
+			want:  6,
+		},
+		"Bounded": {
+			reason: "A schema nested more deeply than the limit should not be walked past the limit.",
+			s:      nested(1000),
+			limit:  10,
+			want:   11,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := maxDepth(tc.s, tc.limit)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nmaxDepth(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithSchemaDepthLimit(t *testing.T) {
+	// schema returns an XRD schema whose spec is nested the supplied number of
+	// levels deep, such that the schema is one level deeper.
+	schema := func(depth int) string {
+		root := extv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]extv1.JSONSchemaProps{"spec": *nested(depth)},
+		}
+		j, _ := json.Marshal(root)
+		return string(j)
+	}
+
+	// chain returns an XRD schema whose spec refers to the first of the
+	// supplied number of definitions, each of which refers to the next.
+	chain := func(n int) string {
+		defs := make([]string, 0, n+1)
+		for i := 0; i < n; i++ {
+			defs = append(defs, fmt.Sprintf(`"d%d":{"type":"object","properties":{"next":{"$ref":"#/definitions/d%d"}}}`, i, i+1))
+		}
+		defs = append(defs, fmt.Sprintf(`"d%d":{"type":"string"}`, n))
+		return `{"definitions":{` + strings.Join(defs, ",") + `},"type":"object","properties":{"spec":{"type":"object","properties":{"chain":{"$ref":"#/definitions/d0"}}}}}`
+	}
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		o      []Option
+		want   error
+	}{
+		"WithinLimit": {
+			reason: "A schema that is exactly as deep as the limit should be accepted.",
+			schema: schema(3),
+			o:      []Option{WithSchemaDepthLimit(4)},
+		},
+		"TooDeep": {
+			reason: "A schema that is deeper than the configured limit should be rejected.",
+			schema: schema(4),
+			o:      []Option{WithSchemaDepthLimit(4)},
+			want:   errors.Errorf(errFmtSchemaDeeperThan, "v1alpha1", 4),
+		},
+		"TooDeepByDefault": {
+			reason: "A schema that is deeper than the default limit should be rejected.",
+			schema: schema(DefaultMaxSchemaDepth),
+			want:   errors.Errorf(errFmtSchemaDeeperThan, "v1alpha1", DefaultMaxSchemaDepth),
+		},
+		"TooDeepViaRefs": {
+			reason: "The limit should apply to the schema once its references are inlined.",
+			schema: chain(40),
+			o:      []Option{WithSchemaDepthLimit(10)},
+			want:   errors.Errorf(errFmtSchemaDeeperThan, "v1alpha1", 10),
+		},
+		"WithinLimitViaRefs": {
+			reason: "A schema whose inlined references are within the limit should be accepted.",
+			schema: chain(5),
+			o:      []Option{WithSchemaDepthLimit(10)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ForCompositeResource(minimalXRD(tc.schema), tc.o...)
+			if diff := cmp.Diff(tc.want, errors.Cause(err), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
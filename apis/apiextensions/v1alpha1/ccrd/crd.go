@@ -23,12 +23,24 @@ limitations under the License.
 package ccrd
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/version"
 
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 
 	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
@@ -40,65 +52,380 @@ const (
 	CategoryComposite = "composite"
 )
 
+// LabelKeyOwnedByXRD is the key of a label that is added to all generated
+// CRDs. Its value is the name of the CompositeResourceDefinition that the CRD
+// was generated from. It allows the CRDs generated from a particular XRD to be
+// selected, e.g. using kubectl get crds -l. Label values may be at most 63
+// characters long, so longer XRD names are shortened; see OwnedByXRDLabelValue.
+const LabelKeyOwnedByXRD = "apiextensions.crossplane.io/owned-by-xrd"
+
+// AnnotationKeyOwnedByXRD is the key of an annotation that is added to
+// generated CRDs when the name of the CompositeResourceDefinition they were
+// generated from is too long to be the value of the LabelKeyOwnedByXRD label.
+// Its value is the full name of the XRD.
+const AnnotationKeyOwnedByXRD = "apiextensions.crossplane.io/owned-by-xrd"
+
+// LabelKeyManagedBy is the key of a label that is added to all generated CRDs.
+// Its value is always LabelValueManagedBy. It signals that the CRD is owned by
+// Crossplane, and that changes made to it by other clients (e.g. kubectl edit)
+// will be overwritten. See also ModifiedBy.
+const LabelKeyManagedBy = "app.kubernetes.io/managed-by"
+
+// LabelValueManagedBy is the value of the LabelKeyManagedBy label.
+const LabelValueManagedBy = "crossplane"
+
+// AnnotationKeyVersions is the key of an annotation that is added to generated
+// CRDs that have more than one version. Its value is a comma separated list of
+// the CRD's versions, in the order they are listed by API discovery. It allows
+// tooling to detect CRDs that will require a conversion webhook.
+const AnnotationKeyVersions = "apiextensions.crossplane.io/versions"
+
+// AnnotationKeyStorageVersion is the key of an annotation that may be added to
+// generated CRDs. Its value is the version in which the CRD's custom resources
+// are stored. It allows tooling to detect when the storage version changes, and
+// thus when existing custom resources should be migrated to the new version.
+const AnnotationKeyStorageVersion = "apiextensions.crossplane.io/storage-version"
+
+// AnnotationKeyDefaultComposition is the key of an annotation that may be added
+// to generated composite resource CRDs. Its value is the name of the
+// Composition intended for composite resources that specify neither a
+// compositionRef nor a compositionSelector. See WithDefaultComposition.
+const AnnotationKeyDefaultComposition = "apiextensions.crossplane.io/default-composition"
+
+// Policies that control how the composite resource of a claim is deleted when
+// the claim is deleted.
+const (
+	CompositeDeletePolicyBackground = "Background"
+	CompositeDeletePolicyForeground = "Foreground"
+)
+
 const (
-	errGetSpecProps            = "cannot get spec properties from validation schema"
-	errParseValidation         = "cannot parse validation schema"
-	errInvalidClaimNames       = "invalid resource claim names"
-	errMissingClaimNames       = "missing names"
-	errFmtConflictingClaimName = "%q conflicts with composite resource name"
+	errGenerateComposite         = "cannot generate composite resource CustomResourceDefinition"
+	errGenerateClaim             = "cannot generate composite resource claim CustomResourceDefinition"
+	errGetSchema                 = "cannot get validation schema"
+	errParseValidation           = "cannot parse validation schema"
+	errResolveRefs               = "cannot resolve schema references"
+	errMarshalEnum               = "cannot marshal enum value"
+	errMarshalDefault            = "cannot marshal default value"
+	errInvalidNames              = "invalid composite resource names"
+	errInvalidDefault            = "invalid default composition"
+	errInvalidClaimNames         = "invalid resource claim names"
+	errMissingClaimNames         = "missing names"
+	errInvalidCRDName            = "invalid CustomResourceDefinition name"
+	errFmtInvalidName            = "%q is not a valid DNS subdomain: %s"
+	errFmtInvalidNameLabel       = "%q is not a valid DNS label: %s"
+	errInvalidVersions           = "invalid versions"
+	errMissingVersions           = "at least one version must be specified"
+	errFmtInvalidVersionName     = "version name %q is not a valid Kubernetes API version, e.g. v1 or v1alpha1"
+	errFmtDuplicateVersion       = "version name %q is not unique"
+	errNoReferenceableVersion    = "exactly one version must be referenceable"
+	errFmtMultipleReferenceable  = "only one version may be referenceable, but both %q and %q are"
+	errFmtUnservedReferenceable  = "referenceable version %q must be served"
+	errFmtConflictingClaimName   = "%q conflicts with composite resource name"
+	errFmtSpecNotObject          = "spec must be of type object, not %q"
+	errFmtReservedSpecProp       = "spec property %q is reserved for the root of the schema"
+	errFmtInjectedSpecProp       = "spec property %q is reserved for a property injected by Crossplane"
+	errFmtInvalidPrinterColumns  = "invalid printer columns for version %q"
+	errFmtDuplicatePrinterColumn = "printer column name %q is not unique"
+	errGetCRDStatus              = "cannot get CustomResourceDefinition status"
+	errWaitEstablished           = "stopped waiting for CustomResourceDefinition to become established"
+	errFmtInvalidInterval        = "poll interval must be positive, not %s"
+	errFmtNamesNotAccepted       = "CustomResourceDefinition names were not accepted: %s: %s"
+	errFmtInvalidDefault         = "default composition %q is not a valid DNS subdomain: %s"
+	errFmtDefaultNotAllowed      = "default composition %q is not an allowed composition"
+	errFmtGenerateXRD            = "cannot generate composite resource CustomResourceDefinition for %q"
+	errFmtEmitXRD                = "cannot emit composite resource CustomResourceDefinition for %q"
+	errFmtVersionNotServed       = "version %q of CustomResourceDefinition %q is not served"
+	errFmtUnknownVersion         = "CustomResourceDefinition %q has no version %q"
+	errFmtUnknownPrinterColumn   = "composite resource printer column %q does not exist"
+	errFmtInvalidDeletePolicy    = "composite delete policy %q must be one of Background or Foreground"
 )
 
+// An Option configures how a CustomResourceDefinition is generated.
+type Option func(*options)
+
+type options struct {
+	compositions            []string
+	managementPolicies      bool
+	requireConnectionSecret bool
+	terminatingColumn       bool
+	omitCategory            bool
+	preserveUnknownSpec     bool
+	defaultComposition      string
+	blockOwnerDeletion      bool
+	pausedField             bool
+	environmentConfigRefs   bool
+	compositeDeletePolicy   string
+	observedGeneration      bool
+	maxSchemaDepth          int
+	storageVersion          bool
+	claimColumns            []string
+	categories              []string
+	finalizers              []string
+	log                     logging.Logger
+}
+
+// newOptions returns the supplied options applied to the default options.
+func newOptions(opts []Option) *options {
+	o := &options{maxSchemaDepth: DefaultMaxSchemaDepth, log: logging.NewNopLogger()}
+	for _, fn := range opts {
+		fn(o)
+	}
+	return o
+}
+
+// WithLogger specifies how CRD generation should log debug messages, e.g. to
+// explain why a CRD could not be generated from an XRD. Nothing is logged by
+// default.
+func WithLogger(l logging.Logger) Option {
+	return func(o *options) {
+		o.log = l
+	}
+}
+
+// WithAllowedCompositions constrains generated composite resources such that
+// they may only reference the supplied Compositions by name. Composite
+// resources may reference any Composition when no names are supplied. Names
+// supplied by repeated uses of this option are all allowed; each is allowed
+// only once, no matter how many times it is supplied.
+func WithAllowedCompositions(names ...string) Option {
+	return func(o *options) {
+		for _, n := range names {
+			if !containsString(o.compositions, n) {
+				o.compositions = append(o.compositions, n)
+			}
+		}
+	}
+}
+
+// WithManagementPolicies injects an optional managementPolicies field into the
+// spec of generated composite resources. The field lists the operations that
+// Crossplane may perform on a composite resource. It is informational; the
+// composite resource reconciler does not read it, so it does not yet pause or
+// limit reconciliation.
+func WithManagementPolicies() Option {
+	return func(o *options) {
+		o.managementPolicies = true
+	}
+}
+
+// WithPausedField injects an optional boolean paused field into the spec of
+// generated composite resources, so that tooling (e.g. kubectl explain) may
+// discover and toggle it. The field is informational; the composite resource
+// reconciler does not read it, so setting it does not pause reconciliation.
+func WithPausedField() Option {
+	return func(o *options) {
+		o.pausedField = true
+	}
+}
+
+// WithEnvironmentConfigRefs injects an optional environmentConfigRefs field
+// into the spec of generated composite resources, so that they may record
+// references to EnvironmentConfigs. The field is informational; the composite
+// resource reconciler does not read it. The field is not injected by default,
+// so that generated CRDs are unchanged for installations that do not use it.
+func WithEnvironmentConfigRefs() Option {
+	return func(o *options) {
+		o.environmentConfigRefs = true
+	}
+}
+
+// WithSchemaDepthLimit limits how deeply the user-defined schema of each XRD
+// version may be nested once its references are inlined. CRDs are not
+// generated for XRDs with schemas that are nested more deeply.
+// DefaultMaxSchemaDepth is used if no limit is supplied.
+func WithSchemaDepthLimit(n int) Option {
+	return func(o *options) {
+		o.maxSchemaDepth = n
+	}
+}
+
+// WithStorageVersionAnnotation annotates generated CRDs with their storage
+// version. See AnnotationKeyStorageVersion and StorageVersionChanged.
+func WithStorageVersionAnnotation() Option {
+	return func(o *options) {
+		o.storageVersion = true
+	}
+}
+
+// WithObservedGeneration injects an optional integer observedGeneration field
+// into the status of generated composite resources, so that tooling may compare
+// it to metadata.generation to determine whether reconciliation is in progress.
+func WithObservedGeneration() Option {
+	return func(o *options) {
+		o.observedGeneration = true
+	}
+}
+
+// WithRequiredConnectionSecret requires generated composite resource claims
+// to specify a writeConnectionSecretToRef, ensuring that their connection
+// secrets are always written. This option has no effect on generated composite
+// resources.
+func WithRequiredConnectionSecret() Option {
+	return func(o *options) {
+		o.requireConnectionSecret = true
+	}
+}
+
+// WithTerminatingColumn adds a TERMINATING printer column to generated
+// composite resources, indicating how long ago a composite resource began
+// being deleted. The column is empty for composite resources that are not
+// being deleted.
+func WithTerminatingColumn() Option {
+	return func(o *options) {
+		o.terminatingColumn = true
+	}
+}
+
+// WithClaimPrinterColumns adds the named printer columns that Crossplane
+// injects into generated composite resources to generated composite resource
+// claims too, so that claims show the same information as their composite
+// resources. For example WithClaimPrinterColumns("COMPOSITION"). Columns that
+// claims already include are not added again. Columns supplied by repeated uses
+// of this option are all added.
+func WithClaimPrinterColumns(names ...string) Option {
+	return func(o *options) {
+		o.claimColumns = append(o.claimColumns, names...)
+	}
+}
+
+// WithDefaultCategories adds the supplied categories to generated composite
+// resources and claims, in addition to the composite or claim category and any
+// categories specified by the XRD. This allows a platform to list all of its
+// composite resources and claims under its own category, e.g. kubectl get acme.
+// Categories supplied by repeated uses of this option are all added.
+func WithDefaultCategories(c ...string) Option {
+	return func(o *options) {
+		o.categories = append(o.categories, c...)
+	}
+}
+
+// WithoutCompositeCategory omits the composite category from generated
+// composite resources, such that they are not listed by kubectl get composite.
+// This is useful for XRDs whose composite resources are deprecated.
+func WithoutCompositeCategory() Option {
+	return func(o *options) {
+		o.omitCategory = true
+	}
+}
+
+// WithPreserveUnknownSpecFields sets x-kubernetes-preserve-unknown-fields on
+// the spec of generated composite resources and composite resource claims.
+// By default api-server prunes any spec field that does not appear in a CRD's
+// schema, which catches typos and keeps stored objects consistent with their
+// schema. Preserving unknown fields instead allows arbitrary data to be passed
+// through the spec, at the cost of that validation; fields that do appear in
+// the schema are still validated.
+func WithPreserveUnknownSpecFields() Option {
+	return func(o *options) {
+		o.preserveUnknownSpec = true
+	}
+}
+
+// WithDefaultComposition annotates generated composite resource CRDs with the
+// supplied Composition name, so that tooling may discover the Composition a
+// platform team intends to be the default. The annotation is informational;
+// Crossplane selects a default Composition using the XRD's
+// defaultCompositionRef. The default is not part of the CRD's schema, because
+// a schema default would also be applied to composite resources that specify
+// a compositionSelector. This option has no effect on generated composite
+// resource claims.
+func WithDefaultComposition(name string) Option {
+	return func(o *options) {
+		o.defaultComposition = name
+	}
+}
+
+// WithCompositeDeletePolicy injects an optional compositeDeletePolicy field
+// into the spec of generated composite resource claims, defaulting to the
+// supplied policy. The policy controls whether a claim's composite resource is
+// deleted in the background or the foreground when the claim is deleted, and
+// must be either CompositeDeletePolicyBackground or
+// CompositeDeletePolicyForeground. This option has no effect on generated
+// composite resources.
+func WithCompositeDeletePolicy(def string) Option {
+	return func(o *options) {
+		o.compositeDeletePolicy = def
+	}
+}
+
+// WithBlockOwnerDeletion sets blockOwnerDeletion on the controller reference
+// from generated CRDs to their XRD. When an XRD is deleted in the foreground,
+// it will not be removed until the CRDs it controls have been deleted. This has
+// no effect on XRDs that are deleted in the background.
+func WithBlockOwnerDeletion() Option {
+	return func(o *options) {
+		o.blockOwnerDeletion = true
+	}
+}
+
+// WithFinalizers adds the supplied finalizers to the metadata of generated
+// CRDs, e.g. to protect them from accidental deletion while composite resources
+// or claims exist. Each finalizer is added only once, no matter how many times
+// it is supplied. Use RemoveFinalizer to release a generated CRD for deletion.
+func WithFinalizers(finalizers ...string) Option {
+	return func(o *options) {
+		o.finalizers = append(o.finalizers, finalizers...)
+	}
+}
+
 // ForCompositeResource derives the CustomResourceDefinition for a composite
 // resource from the supplied CompositeResourceDefinition.
-func ForCompositeResource(xrd *v1alpha1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+func ForCompositeResource(xrd *v1alpha1.CompositeResourceDefinition, opts ...Option) (*extv1.CustomResourceDefinition, error) {
+	o := newOptions(opts)
+	o.log = o.log.WithValues("xrd", xrd.GetName(), "crd", xrd.GetName())
+
+	if err := validateVersions(xrd); err != nil {
+		o.log.Debug(errInvalidVersions, "error", err)
+		return nil, errors.Wrap(err, errInvalidVersions)
+	}
+
+	if err := ValidateNames(xrd.Spec.Names); err != nil {
+		o.log.Debug(errInvalidNames, "error", err)
+		return nil, errors.Wrap(err, errInvalidNames)
+	}
+
+	if err := validateDefaultComposition(o.defaultComposition, o.compositions); err != nil {
+		o.log.Debug(errInvalidDefault, "error", err)
+		return nil, errors.Wrap(err, errInvalidDefault)
+	}
+
 	crd := &extv1.CustomResourceDefinition{
 		Spec: extv1.CustomResourceDefinitionSpec{
-			Scope:    extv1.ClusterScoped,
-			Group:    xrd.Spec.Group,
-			Names:    xrd.Spec.Names,
-			Versions: make([]extv1.CustomResourceDefinitionVersion, len(xrd.Spec.Versions)),
+			Scope:      extv1.ClusterScoped,
+			Group:      xrd.Spec.Group,
+			Names:      xrd.Spec.Names,
+			Versions:   make([]extv1.CustomResourceDefinitionVersion, len(xrd.Spec.Versions)),
+			Conversion: conversionFor(xrd),
 		},
 	}
 
 	crd.SetName(xrd.GetName())
-	crd.SetLabels(xrd.GetLabels())
-	crd.SetAnnotations(xrd.GetAnnotations())
-	crd.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(
-		meta.TypedReferenceTo(xrd, v1alpha1.CompositeResourceDefinitionGroupVersionKind),
-	)})
+	crd.SetLabels(labelsFor(xrd))
+	crd.SetAnnotations(ownerAnnotationsFor(xrd, annotationsFor(xrd, o)))
+	crd.SetOwnerReferences(ownerReferencesFor(xrd, o))
+	for _, f := range o.finalizers {
+		meta.AddFinalizer(crd, f)
+	}
+	if o.defaultComposition != "" {
+		crd.SetAnnotations(withAnnotation(crd.GetAnnotations(), AnnotationKeyDefaultComposition, o.defaultComposition))
+	}
 
-	crd.Spec.Names.Categories = append(crd.Spec.Names.Categories, CategoryComposite)
+	builtin := []string{CategoryComposite}
+	if o.omitCategory {
+		builtin = nil
+	}
+	crd.Spec.Names.Categories = categoriesFor(crd.Spec.Names.Categories, o, builtin...)
 
 	for i, vr := range xrd.Spec.Versions {
-		crd.Spec.Versions[i] = extv1.CustomResourceDefinitionVersion{
-			Name:                     vr.Name,
-			Served:                   vr.Served,
-			Storage:                  vr.Referenceable,
-			AdditionalPrinterColumns: append(vr.AdditionalPrinterColumns, CompositeResourcePrinterColumns()...),
-			Schema: &extv1.CustomResourceValidation{
-				OpenAPIV3Schema: &extv1.JSONSchemaProps{
-					Type:       "object",
-					Properties: BaseProps(),
-				},
-			},
-			Subresources: &extv1.CustomResourceSubresources{
-				Status: &extv1.CustomResourceSubresourceStatus{},
-			},
-		}
-
-		p, err := getSpecProps(vr.Schema)
+		v, err := forVersion(vr, o, compositePrinterColumns(o), compositeStatusProps(o), func(root *extv1.JSONSchemaProps) error {
+			preserveUnknownSpecFields(root, o)
+			return injectSpecProps(root.Properties["spec"].Properties, o)
+		})
 		if err != nil {
-			return nil, errors.Wrap(err, errGetSpecProps)
-		}
-		for k, v := range p {
-			crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["spec"].Properties[k] = v
-		}
-		for k, v := range CompositeResourceSpecProps() {
-			crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["spec"].Properties[k] = v
-		}
-		for k, v := range CompositeResourceStatusProps() {
-			crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["status"].Properties[k] = v
+			return nil, err
 		}
+		crd.Spec.Versions[i] = *v
 	}
 
 	return crd, nil
@@ -106,104 +433,813 @@ func ForCompositeResource(xrd *v1alpha1.CompositeResourceDefinition) (*extv1.Cus
 
 // ForCompositeResourceClaim derives the CustomResourceDefinition for a
 // composite resource claim from the supplied CompositeResourceDefinition.
-func ForCompositeResourceClaim(xrd *v1alpha1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+func ForCompositeResourceClaim(xrd *v1alpha1.CompositeResourceDefinition, opts ...Option) (*extv1.CustomResourceDefinition, error) {
+	o := newOptions(opts)
+	o.log = o.log.WithValues("xrd", xrd.GetName())
+
+	if err := validateVersions(xrd); err != nil {
+		o.log.Debug(errInvalidVersions, "error", err)
+		return nil, errors.Wrap(err, errInvalidVersions)
+	}
+
 	if err := validateClaimNames(xrd); err != nil {
+		o.log.Debug(errInvalidClaimNames, "error", err)
+		return nil, errors.Wrap(err, errInvalidClaimNames)
+	}
+
+	if err := ValidateNames(*xrd.Spec.ClaimNames); err != nil {
+		o.log.Debug(errInvalidClaimNames, "error", err)
 		return nil, errors.Wrap(err, errInvalidClaimNames)
 	}
 
 	crd := &extv1.CustomResourceDefinition{
 		Spec: extv1.CustomResourceDefinitionSpec{
-			Scope:    extv1.NamespaceScoped,
-			Group:    xrd.Spec.Group,
-			Names:    *xrd.Spec.ClaimNames,
-			Versions: make([]extv1.CustomResourceDefinitionVersion, len(xrd.Spec.Versions)),
+			Scope:      extv1.NamespaceScoped,
+			Group:      xrd.Spec.Group,
+			Names:      *xrd.Spec.ClaimNames,
+			Versions:   make([]extv1.CustomResourceDefinitionVersion, len(xrd.Spec.Versions)),
+			Conversion: conversionFor(xrd),
 		},
 	}
 
-	crd.SetName(xrd.Spec.ClaimNames.Plural + "." + xrd.Spec.Group)
-	crd.SetLabels(xrd.GetLabels())
-	crd.SetAnnotations(xrd.GetAnnotations())
-	crd.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(
-		meta.TypedReferenceTo(xrd, v1alpha1.CompositeResourceDefinitionGroupVersionKind),
-	)})
+	name := xrd.Spec.ClaimNames.Plural + "." + xrd.Spec.Group
+	o.log = o.log.WithValues("crd", name)
+	if err := validateCRDName(name); err != nil {
+		o.log.Debug(errInvalidCRDName, "error", err)
+		return nil, errors.Wrap(err, errInvalidCRDName)
+	}
+
+	crd.SetName(name)
+	crd.SetLabels(labelsFor(xrd))
+	crd.SetAnnotations(ownerAnnotationsFor(xrd, annotationsFor(xrd, o)))
+	crd.SetOwnerReferences(ownerReferencesFor(xrd, o))
+	for _, f := range o.finalizers {
+		meta.AddFinalizer(crd, f)
+	}
 
-	crd.Spec.Names.Categories = append(crd.Spec.Names.Categories, CategoryClaim)
+	crd.Spec.Names.Categories = categoriesFor(crd.Spec.Names.Categories, o, CategoryClaim)
+
+	cols, err := claimPrinterColumns(o)
+	if err != nil {
+		return nil, err
+	}
 
 	for i, vr := range xrd.Spec.Versions {
-		crd.Spec.Versions[i] = extv1.CustomResourceDefinitionVersion{
-			Name:                     vr.Name,
-			Served:                   vr.Served,
-			Storage:                  vr.Referenceable,
-			AdditionalPrinterColumns: append(vr.AdditionalPrinterColumns, CompositeResourceClaimPrinterColumns()...),
-			Schema: &extv1.CustomResourceValidation{
-				OpenAPIV3Schema: &extv1.JSONSchemaProps{
-					Type:       "object",
-					Properties: BaseProps(),
-				},
-			},
-			Subresources: &extv1.CustomResourceSubresources{
-				Status: &extv1.CustomResourceSubresourceStatus{},
+		v, err := forVersion(vr, o, cols, CompositeResourceStatusProps(), func(root *extv1.JSONSchemaProps) error {
+			preserveUnknownSpecFields(root, o)
+			return injectClaimSpecProps(root, o)
+		})
+		if err != nil {
+			return nil, err
+		}
+		crd.Spec.Versions[i] = *v
+	}
+
+	return crd, nil
+}
+
+// forVersion derives a CustomResourceDefinitionVersion from the supplied XRD
+// version. The supplied printer columns are appended to any the version
+// specifies, the supplied status properties are merged with any the version
+// specifies, and inject is called to add Crossplane's spec properties to the
+// version's schema after the user's spec properties have been merged in. The
+// supplied options limit how deeply the version's schema may be nested, and
+// determine how its derivation is logged.
+func forVersion(vr v1alpha1.CompositeResourceDefinitionVersion, o *options, injectedCols []extv1.CustomResourceColumnDefinition, injectedStatus map[string]extv1.JSONSchemaProps, inject func(root *extv1.JSONSchemaProps) error) (*extv1.CustomResourceDefinitionVersion, error) {
+	log := o.log.WithValues("version", vr.Name)
+
+	cols, err := printerColumns(vr.AdditionalPrinterColumns, injectedCols)
+	if err != nil {
+		log.Debug("Cannot merge printer columns", "error", err)
+		return nil, errors.Wrapf(err, errFmtInvalidPrinterColumns, vr.Name)
+	}
+
+	v := &extv1.CustomResourceDefinitionVersion{
+		Name:                     vr.Name,
+		Served:                   vr.Served,
+		Storage:                  vr.Referenceable,
+		AdditionalPrinterColumns: cols,
+		Schema: &extv1.CustomResourceValidation{
+			OpenAPIV3Schema: &extv1.JSONSchemaProps{
+				Type:       "object",
+				Properties: BaseProps(),
 			},
+		},
+		Subresources: &extv1.CustomResourceSubresources{
+			Status: &extv1.CustomResourceSubresourceStatus{},
+		},
+	}
+
+	user, err := getUserSchema(vr, o.maxSchemaDepth)
+	if err != nil {
+		log.Debug(errGetSchema, "error", err)
+		return nil, errors.Wrap(err, errGetSchema)
+	}
+
+	root := v.Schema.OpenAPIV3Schema
+	root.Description = user.Description
+	if err := mergeSpecProps(root, user.Properties["spec"]); err != nil {
+		log.Debug("Cannot merge user-defined spec properties", "error", err)
+		return nil, err
+	}
+	if err := inject(root); err != nil {
+		log.Debug("Cannot inject spec properties", "error", err)
+		return nil, err
+	}
+	log.Debug("Merged spec properties", "user", len(user.Properties["spec"].Properties), "total", len(root.Properties["spec"].Properties))
+
+	if ignored := mergeStatusProps(root.Properties["status"].Properties, user.Properties["status"].Properties, injectedStatus); len(ignored) > 0 {
+		log.Debug("Ignoring user-defined status properties that conflict with injected status properties", "ignored", ignored)
+	}
+	log.Debug("Merged status properties", "user", len(user.Properties["status"].Properties), "total", len(root.Properties["status"].Properties))
+
+	return v, nil
+}
+
+// labelsFor returns the labels of a CRD generated from the supplied XRD. The
+// XRD's labels are copied, rather than shared, so that Crossplane may add its
+// own labels without modifying the XRD.
+func labelsFor(xrd *v1alpha1.CompositeResourceDefinition) map[string]string {
+	return mergeLabels(map[string]string{
+		LabelKeyOwnedByXRD: OwnedByXRDLabelValue(xrd.GetName()),
+		LabelKeyManagedBy:  LabelValueManagedBy,
+	}, xrd.GetLabels())
+}
+
+// OwnedByXRDLabelValue returns the value of the LabelKeyOwnedByXRD label of
+// the CRDs generated from the XRD with the supplied name. This is the XRD's
+// name, unless it is longer than a label value may be. Longer names are
+// truncated and suffixed with a hash of the full name, so that the value
+// remains unique to the XRD.
+func OwnedByXRDLabelValue(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+	h := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(h[:])[:8]
+	return name[:validation.LabelValueMaxLength-len(suffix)-1] + "-" + suffix
+}
+
+// ownerAnnotationsFor returns the supplied annotations of a CRD generated from
+// the supplied XRD, with the AnnotationKeyOwnedByXRD annotation added if the
+// XRD's name could not be used as its owned-by label value.
+func ownerAnnotationsFor(xrd *v1alpha1.CompositeResourceDefinition, a map[string]string) map[string]string {
+	if OwnedByXRDLabelValue(xrd.GetName()) == xrd.GetName() {
+		return a
+	}
+	return withAnnotation(a, AnnotationKeyOwnedByXRD, xrd.GetName())
+}
+
+// mergeLabels returns a new map containing the labels of both base and
+// overlay. The keys of base are reserved; their values take precedence over
+// any value overlay supplies for the same key. Neither map is modified.
+func mergeLabels(base, overlay map[string]string) map[string]string {
+	l := make(map[string]string, len(base)+len(overlay))
+	for k, v := range overlay {
+		l[k] = v
+	}
+	for k, v := range base {
+		l[k] = v
+	}
+	return l
+}
+
+// RemoveFinalizer removes the supplied finalizer from the supplied CRD. It
+// returns true if the CRD had the finalizer, in which case the caller should
+// update the CRD.
+func RemoveFinalizer(crd *extv1.CustomResourceDefinition, finalizer string) bool {
+	if !meta.FinalizerExists(crd, finalizer) {
+		return false
+	}
+	meta.RemoveFinalizer(crd, finalizer)
+	return true
+}
+
+// ownerReferencesFor returns the owner references of the CRDs generated from
+// the supplied XRD, as configured by the supplied options.
+func ownerReferencesFor(xrd *v1alpha1.CompositeResourceDefinition, o *options) []metav1.OwnerReference {
+	ref := meta.AsController(meta.TypedReferenceTo(xrd, v1alpha1.CompositeResourceDefinitionGroupVersionKind))
+	if o.blockOwnerDeletion {
+		block := true
+		ref.BlockOwnerDeletion = &block
+	}
+	return []metav1.OwnerReference{ref}
+}
+
+// annotationsFor returns the annotations of the CRDs generated from the
+// supplied XRD, as configured by the supplied options.
+func annotationsFor(xrd *v1alpha1.CompositeResourceDefinition, o *options) map[string]string {
+	if len(xrd.Spec.Versions) < 2 && !o.storageVersion {
+		return xrd.GetAnnotations()
+	}
+	a := make(map[string]string, len(xrd.GetAnnotations())+2)
+	for k, v := range xrd.GetAnnotations() {
+		a[k] = v
+	}
+	if o.storageVersion {
+		a[AnnotationKeyStorageVersion] = referenceableVersion(xrd)
+	}
+	if len(xrd.Spec.Versions) < 2 {
+		return a
+	}
+	v := make([]string, len(xrd.Spec.Versions))
+	for i, vr := range xrd.Spec.Versions {
+		v[i] = vr.Name
+	}
+	sort.SliceStable(v, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(v[i], v[j]) > 0
+	})
+	a[AnnotationKeyVersions] = strings.Join(v, ",")
+	return a
+}
+
+// conversionFor returns the conversion settings of the CRDs generated from the
+// supplied XRD. The ConversionReview versions a conversion webhook supports
+// default to v1, which api-server requires when a webhook is configured.
+func conversionFor(xrd *v1alpha1.CompositeResourceDefinition) *extv1.CustomResourceConversion {
+	if xrd.Spec.Conversion == nil {
+		return nil
+	}
+	c := xrd.Spec.Conversion.DeepCopy()
+	if c.Strategy != extv1.WebhookConverter {
+		return c
+	}
+	if c.Webhook == nil {
+		c.Webhook = &extv1.WebhookConversion{}
+	}
+	if len(c.Webhook.ConversionReviewVersions) == 0 {
+		c.Webhook.ConversionReviewVersions = []string{"v1"}
+	}
+	return c
+}
+
+// withAnnotation returns a copy of the supplied annotations with the supplied
+// annotation added. The supplied annotations may belong to an XRD, so they are
+// never modified.
+func withAnnotation(a map[string]string, k, v string) map[string]string {
+	out := make(map[string]string, len(a)+1)
+	for ak, av := range a {
+		out[ak] = av
+	}
+	out[k] = v
+	return out
+}
+
+// referenceableVersion returns the name of the supplied XRD's referenceable
+// version, which is the storage version of the CRDs generated from it.
+func referenceableVersion(xrd *v1alpha1.CompositeResourceDefinition) string {
+	for _, vr := range xrd.Spec.Versions {
+		if vr.Referenceable {
+			return vr.Name
 		}
+	}
+	return ""
+}
+
+// StorageVersionChanged returns true if the storage version of the supplied old
+// CRD differs from that of the supplied new CRD, in which case existing custom
+// resources should be migrated to the new storage version. The storage version
+// of each CRD is read from its storage version annotation, or from its versions
+// if it is not annotated. A CRD with no storage version has not changed it.
+func StorageVersionChanged(old, new *extv1.CustomResourceDefinition) bool {
+	o, n := storageVersion(old), storageVersion(new)
+	return o != "" && n != "" && o != n
+}
 
-		p, err := getSpecProps(vr.Schema)
+func storageVersion(crd *extv1.CustomResourceDefinition) string {
+	if v := crd.GetAnnotations()[AnnotationKeyStorageVersion]; v != "" {
+		return v
+	}
+	for _, vr := range crd.Spec.Versions {
+		if vr.Storage {
+			return vr.Name
+		}
+	}
+	return ""
+}
+
+// ParseVersions returns the versions recorded by the versions annotation of
+// the supplied CRD, in the order they were recorded. It returns nil if the CRD
+// does not have a versions annotation.
+func ParseVersions(crd *extv1.CustomResourceDefinition) []string {
+	a, ok := crd.GetAnnotations()[AnnotationKeyVersions]
+	if !ok || a == "" {
+		return nil
+	}
+	return strings.Split(a, ",")
+}
+
+// ForXRD derives the CustomResourceDefinitions for a composite resource and,
+// if the supplied CompositeResourceDefinition offers one, its composite
+// resource claim. The returned claim CRD is nil if no claim is offered.
+func ForXRD(xrd *v1alpha1.CompositeResourceDefinition, o ...Option) (composite, claim *extv1.CustomResourceDefinition, err error) {
+	composite, err = ForCompositeResource(xrd, o...)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerateComposite)
+	}
+
+	if !xrd.OffersClaim() {
+		return composite, nil, nil
+	}
+
+	claim, err = ForCompositeResourceClaim(xrd, o...)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGenerateClaim)
+	}
+
+	return composite, claim, nil
+}
+
+// ForCompositeResources derives the CustomResourceDefinition for the composite
+// resource of each of the supplied CompositeResourceDefinitions, in order, and
+// passes each to the supplied emit function as soon as it is generated. It
+// stops at the first XRD that cannot be generated or emitted, returning an
+// error that includes the name of that XRD.
+func ForCompositeResources(xrds []*v1alpha1.CompositeResourceDefinition, emit func(*extv1.CustomResourceDefinition) error, o ...Option) error {
+	for _, xrd := range xrds {
+		crd, err := ForCompositeResource(xrd, o...)
 		if err != nil {
-			return nil, errors.Wrap(err, errGetSpecProps)
+			return errors.Wrapf(err, errFmtGenerateXRD, xrd.GetName())
+		}
+		if err := emit(crd); err != nil {
+			return errors.Wrapf(err, errFmtEmitXRD, xrd.GetName())
+		}
+	}
+	return nil
+}
+
+// validateCRDName validates that the supplied name will be accepted by
+// api-server as the name of a CustomResourceDefinition, i.e. that it is a DNS
+// subdomain of no more than 253 characters, and that each of its DNS labels are
+// no more than 63 characters.
+func validateCRDName(name string) error {
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return errors.Errorf(errFmtInvalidName, name, strings.Join(errs, ", "))
+	}
+
+	for _, l := range strings.Split(name, ".") {
+		if errs := validation.IsDNS1123Label(l); len(errs) > 0 {
+			return errors.Errorf(errFmtInvalidNameLabel, l, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+func validateVersions(d *v1alpha1.CompositeResourceDefinition) error {
+	if len(d.Spec.Versions) == 0 {
+		return errors.New(errMissingVersions)
+	}
+
+	if err := validateVersionNames(d.Spec.Versions); err != nil {
+		return err
+	}
+
+	// The referenceable version becomes the storage version of the generated
+	// CRD. api-server requires that exactly one version be stored, and we
+	// require that it be served.
+	ref := ""
+	for _, vr := range d.Spec.Versions {
+		if !vr.Referenceable {
+			continue
+		}
+		if ref != "" {
+			return errors.Errorf(errFmtMultipleReferenceable, ref, vr.Name)
 		}
-		for k, v := range p {
-			crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["spec"].Properties[k] = v
+		if !vr.Served {
+			return errors.Errorf(errFmtUnservedReferenceable, vr.Name)
 		}
-		for k, v := range CompositeResourceClaimSpecProps() {
-			crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["spec"].Properties[k] = v
+		ref = vr.Name
+	}
+
+	if ref == "" {
+		return errors.New(errNoReferenceableVersion)
+	}
+
+	return nil
+}
+
+// kubeVersion matches Kubernetes API version names, e.g. v1 or v1alpha1.
+var kubeVersion = regexp.MustCompile(`^v[1-9][0-9]*((alpha|beta)[1-9][0-9]*)?$`)
+
+// validateVersionNames validates that each of the supplied versions has a
+// unique name that Kubernetes recognises as an API version, and thus that
+// api-server will accept and sort it as expected.
+func validateVersionNames(versions []v1alpha1.CompositeResourceDefinitionVersion) error {
+	seen := make(map[string]bool, len(versions))
+	for _, vr := range versions {
+		if !kubeVersion.MatchString(vr.Name) {
+			return errors.Errorf(errFmtInvalidVersionName, vr.Name)
 		}
-		for k, v := range CompositeResourceStatusProps() {
-			crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["status"].Properties[k] = v
+		if seen[vr.Name] {
+			return errors.Errorf(errFmtDuplicateVersion, vr.Name)
 		}
+		seen[vr.Name] = true
 	}
+	return nil
+}
 
-	return crd, nil
+// Reasons a ClaimNameError may occur.
+const (
+	ClaimNameReasonMissing     = "Missing"
+	ClaimNameReasonConflicting = "Conflicting"
+)
+
+// A ClaimNameError indicates that the claim names of an XRD are invalid.
+type ClaimNameError struct {
+	// Field is the claim name field that is invalid, e.g. kind or plural. It
+	// is empty if the claim names are missing entirely.
+	Field string
+
+	// Value of the invalid field.
+	Value string
+
+	// Reason the field is invalid.
+	Reason string
+}
+
+func (e *ClaimNameError) Error() string {
+	if e.Reason == ClaimNameReasonMissing {
+		return errMissingClaimNames
+	}
+	return fmt.Sprintf(errFmtConflictingClaimName, e.Value)
+}
+
+func conflictingClaimName(field, value string) error {
+	return &ClaimNameError{Field: field, Value: value, Reason: ClaimNameReasonConflicting}
 }
 
+// validateClaimNames validates the claim names of the supplied XRD. Any error
+// it returns is a *ClaimNameError.
 func validateClaimNames(d *v1alpha1.CompositeResourceDefinition) error {
 	if d.Spec.ClaimNames == nil {
-		return errors.New(errMissingClaimNames)
+		return &ClaimNameError{Reason: ClaimNameReasonMissing}
 	}
 
 	if n := d.Spec.ClaimNames.Kind; n == d.Spec.Names.Kind {
-		return errors.Errorf(errFmtConflictingClaimName, n)
+		return conflictingClaimName("kind", n)
 	}
 
 	if n := d.Spec.ClaimNames.Plural; n == d.Spec.Names.Plural {
-		return errors.Errorf(errFmtConflictingClaimName, n)
+		return conflictingClaimName("plural", n)
 	}
 
 	if n := d.Spec.ClaimNames.Singular; n != "" && n == d.Spec.Names.Singular {
-		return errors.Errorf(errFmtConflictingClaimName, n)
+		return conflictingClaimName("singular", n)
 	}
 
 	if n := d.Spec.ClaimNames.ListKind; n != "" && n == d.Spec.Names.ListKind {
-		return errors.Errorf(errFmtConflictingClaimName, n)
+		return conflictingClaimName("listKind", n)
+	}
+
+	if n := d.Spec.ClaimNames.Kind; n == listKind(d.Spec.Names) {
+		return conflictingClaimName("kind", n)
+	}
+
+	if n := listKind(*d.Spec.ClaimNames); n == d.Spec.Names.Kind {
+		return conflictingClaimName("listKind", n)
+	}
+
+	return validateClaimShortNames(d)
+}
+
+// validateClaimShortNames validates that the short names of the supplied XRD's
+// claims do not conflict with the resource names of its composite resources,
+// and vice versa. Any error it returns is a *ClaimNameError.
+func validateClaimShortNames(d *v1alpha1.CompositeResourceDefinition) error {
+	composite := append([]string{d.Spec.Names.Plural, d.Spec.Names.Singular}, d.Spec.Names.ShortNames...)
+	for _, n := range d.Spec.ClaimNames.ShortNames {
+		if containsString(composite, n) {
+			return conflictingClaimName("shortNames", n)
+		}
+	}
+
+	claim := []string{d.Spec.ClaimNames.Plural, d.Spec.ClaimNames.Singular}
+	for _, n := range d.Spec.Names.ShortNames {
+		if containsString(claim, n) {
+			return conflictingClaimName("shortNames", n)
+		}
+	}
+
+	return nil
+}
+
+// listKind returns the list kind of the supplied names, defaulting to the kind
+// suffixed with 'List' as api-server does if no list kind was specified.
+func listKind(n extv1.CustomResourceDefinitionNames) string {
+	if n.ListKind != "" {
+		return n.ListKind
+	}
+	return n.Kind + "List"
+}
+
+// getUserSchema returns the user-defined schema of the supplied version, with
+// any references to its definitions inlined, or an empty schema if the version
+// does not define one. An error is returned if the schema is nested more than
+// depthLimit levels deep once its references are inlined; a chain of
+// references may produce a schema much deeper than the one written. The depth
+// is checked before the schema is merged, which recurses into nested schemas.
+func getUserSchema(vr v1alpha1.CompositeResourceDefinitionVersion, depthLimit int) (extv1.JSONSchemaProps, error) {
+	s, err := getSchema(vr)
+	if err != nil || s == nil {
+		return extv1.JSONSchemaProps{}, err
+	}
+	if err := resolveRefs(s); err != nil {
+		return extv1.JSONSchemaProps{}, errors.Wrap(err, errResolveRefs)
+	}
+	if maxDepth(s, depthLimit) > depthLimit {
+		return extv1.JSONSchemaProps{}, errors.Errorf(errFmtSchemaDeeperThan, vr.Name, depthLimit)
+	}
+	return *s, nil
+}
+
+// mergeSpecProps merges the supplied user-defined spec schema into the spec of
+// the supplied root schema. The user-defined spec schema must be of type
+// object, or have no type. It may not declare the properties that belong at the
+// root of the schema, such as metadata; doing so is a common mistake.
+func mergeSpecProps(root *extv1.JSONSchemaProps, user extv1.JSONSchemaProps) error {
+	if user.Type != "" && user.Type != "object" {
+		return errors.Errorf(errFmtSpecNotObject, user.Type)
+	}
+	for _, k := range []string{"apiVersion", "kind", "metadata", "status"} {
+		if _, ok := user.Properties[k]; ok {
+			return errors.Errorf(errFmtReservedSpecProp, k)
+		}
+	}
+	setSpecKeywords(root, user)
+	for k, v := range user.Properties {
+		root.Properties["spec"].Properties[k] = v
+	}
+	return nil
+}
+
+// setSpecKeywords sets keywords from the supplied user-defined spec schema,
+// other than its properties, on the spec schema of the supplied root schema.
+func setSpecKeywords(root *extv1.JSONSchemaProps, user extv1.JSONSchemaProps) {
+	spec := root.Properties["spec"]
+	spec.OneOf = user.OneOf
+	spec.AnyOf = user.AnyOf
+	spec.AllOf = user.AllOf
+	spec.Not = user.Not
+	spec.Example = user.Example
+	spec.Title = user.Title
+	root.Properties["spec"] = spec
+}
+
+// injectSpecProps injects the spec properties Crossplane requires of all
+// composite resources into dst, as configured by the supplied options.
+func injectSpecProps(dst map[string]extv1.JSONSchemaProps, o *options) error {
+	for k, v := range CompositeResourceSpecProps() {
+		dst[k] = v
+	}
+	if o.managementPolicies {
+		if err := injectOptionalSpecProps(dst, ManagementPoliciesProps()); err != nil {
+			return err
+		}
+	}
+	if o.pausedField {
+		if err := injectOptionalSpecProps(dst, PausedProps()); err != nil {
+			return err
+		}
+	}
+	if o.environmentConfigRefs {
+		if err := injectOptionalSpecProps(dst, EnvironmentConfigRefsProps()); err != nil {
+			return err
+		}
+	}
+	return allowCompositions(dst, o.compositions)
+}
+
+// injectOptionalSpecProps injects the supplied optional spec properties into
+// dst. Unlike the properties Crossplane requires of all composite resources,
+// optional properties are not silently overwritten; an error is returned if
+// dst already has a property of the same name.
+func injectOptionalSpecProps(dst, props map[string]extv1.JSONSchemaProps) error {
+	for k, v := range props {
+		if _, ok := dst[k]; ok {
+			return errors.Errorf(errFmtInjectedSpecProp, k)
+		}
+		dst[k] = v
+	}
+	return nil
+}
+
+// injectClaimSpecProps injects the spec properties Crossplane requires of all
+// composite resource claims into the supplied root schema, as configured by the
+// supplied options.
+func injectClaimSpecProps(root *extv1.JSONSchemaProps, o *options) error {
+	spec := root.Properties["spec"]
+	for k, v := range CompositeResourceClaimSpecProps() {
+		spec.Properties[k] = v
+	}
+	if o.requireConnectionSecret {
+		spec.Required = append(spec.Required, "writeConnectionSecretToRef")
+	}
+	root.Properties["spec"] = spec
+	return injectCompositeDeletePolicy(spec.Properties, o.compositeDeletePolicy)
+}
+
+// injectCompositeDeletePolicy injects the compositeDeletePolicy spec property,
+// defaulting it to the supplied policy. Nothing is injected if no policy is
+// supplied.
+func injectCompositeDeletePolicy(spec map[string]extv1.JSONSchemaProps, def string) error {
+	if def == "" {
+		return nil
+	}
+	if def != CompositeDeletePolicyBackground && def != CompositeDeletePolicyForeground {
+		return errors.Errorf(errFmtInvalidDeletePolicy, def)
+	}
+
+	raw, err := json.Marshal(def)
+	if err != nil {
+		return errors.Wrap(err, errMarshalDefault)
+	}
+	for k, v := range CompositeDeletePolicyProps() {
+		v.Default = &extv1.JSON{Raw: raw}
+		spec[k] = v
+	}
+
+	return nil
+}
+
+// preserveUnknownSpecFields sets x-kubernetes-preserve-unknown-fields on the
+// spec schema of the supplied root schema, if the supplied options call for it.
+func preserveUnknownSpecFields(root *extv1.JSONSchemaProps, o *options) {
+	if !o.preserveUnknownSpec {
+		return
+	}
+	spec := root.Properties["spec"]
+	preserve := true
+	spec.XPreserveUnknownFields = &preserve
+	root.Properties["spec"] = spec
+}
+
+// compositePrinterColumns returns the printer columns Crossplane injects into
+// composite resources, as configured by the supplied options.
+func compositePrinterColumns(o *options) []extv1.CustomResourceColumnDefinition {
+	cols := CompositeResourcePrinterColumns()
+	if o.terminatingColumn {
+		cols = append(cols, TerminatingPrinterColumns()...)
 	}
+	return cols
+}
+
+// compositeStatusProps returns the status properties Crossplane injects into
+// composite resources, as configured by the supplied options.
+func compositeStatusProps(o *options) map[string]extv1.JSONSchemaProps {
+	props := CompositeResourceStatusProps()
+	if o.observedGeneration {
+		for k, v := range ObservedGenerationProps() {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// claimPrinterColumns returns the printer columns Crossplane injects into
+// composite resource claims, followed by any composite resource printer columns
+// the supplied options add to claims. It returns an error if the options add a
+// column that Crossplane does not inject into composite resources.
+func claimPrinterColumns(o *options) ([]extv1.CustomResourceColumnDefinition, error) {
+	cols := CompositeResourceClaimPrinterColumns()
+	composite := compositePrinterColumns(o)
+	for _, name := range o.claimColumns {
+		col, ok := findPrinterColumn(composite, name)
+		if !ok {
+			return nil, errors.Errorf(errFmtUnknownPrinterColumn, name)
+		}
+		if _, ok := findPrinterColumn(cols, name); ok {
+			continue
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+func findPrinterColumn(cols []extv1.CustomResourceColumnDefinition, name string) (extv1.CustomResourceColumnDefinition, bool) {
+	for _, col := range cols {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return extv1.CustomResourceColumnDefinition{}, false
+}
+
+// printerColumns returns the supplied user-defined printer columns followed by
+// the supplied injected printer columns. Injected columns always have priority
+// 0, so that they are shown in both standard and wide output. It returns an
+// error if any two columns share a name.
+func printerColumns(user, injected []extv1.CustomResourceColumnDefinition) ([]extv1.CustomResourceColumnDefinition, error) {
+	cols := make([]extv1.CustomResourceColumnDefinition, 0, len(user)+len(injected))
+	cols = append(cols, user...)
+	for _, c := range injected {
+		c.Priority = 0
+		cols = append(cols, c)
+	}
+
+	seen := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		if seen[c.Name] {
+			return nil, errors.Errorf(errFmtDuplicatePrinterColumn, c.Name)
+		}
+		seen[c.Name] = true
+	}
+
+	return cols, nil
+}
+
+// allowCompositions constrains the injected compositionRef spec property such
+// that it may only reference the supplied Composition names.
+func allowCompositions(spec map[string]extv1.JSONSchemaProps, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	ref := spec["compositionRef"]
+	name := ref.Properties["name"]
+	name.Enum = make([]extv1.JSON, len(names))
+	for i, n := range names {
+		raw, err := json.Marshal(n)
+		if err != nil {
+			return errors.Wrap(err, errMarshalEnum)
+		}
+		name.Enum[i] = extv1.JSON{Raw: raw}
+	}
+	ref.Properties["name"] = name
+	spec["compositionRef"] = ref
 
 	return nil
 }
 
-func getSpecProps(v *v1alpha1.CompositeResourceValidation) (map[string]extv1.JSONSchemaProps, error) {
-	if v == nil {
-		return nil, nil
+// validateDefaultComposition validates that the supplied default Composition
+// name, if any, is a valid object name and, if any are supplied, one of the
+// allowed Composition names.
+func validateDefaultComposition(name string, allowed []string) error {
+	if name == "" {
+		return nil
 	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return errors.Errorf(errFmtInvalidDefault, name, strings.Join(errs, ", "))
+	}
+	if len(allowed) > 0 && !containsString(allowed, name) {
+		return errors.Errorf(errFmtDefaultNotAllowed, name)
+	}
+	return nil
+}
 
-	s := &extv1.JSONSchemaProps{}
-	if err := json.Unmarshal(v.OpenAPIV3Schema.Raw, s); err != nil {
-		return nil, errors.Wrap(err, errParseValidation)
+// categoriesFor returns the supplied categories followed by the supplied
+// built-in categories, then by the default categories of the supplied options.
+func categoriesFor(existing []string, o *options, builtin ...string) []string {
+	categories := existing
+	for _, c := range append(builtin, o.categories...) {
+		categories = appendCategory(categories, c)
 	}
+	return categories
+}
 
-	spec, ok := s.Properties["spec"]
-	if !ok {
-		return nil, nil
+// appendCategory returns a copy of the supplied categories with the supplied
+// category appended, unless a category that is equal to it ignoring case is
+// already present. The order of the supplied categories is preserved. The
+// supplied categories are copied because they may belong to an XRD.
+func appendCategory(existing []string, add string) []string {
+	categories := make([]string, 0, len(existing)+1)
+	categories = append(categories, existing...)
+	for _, c := range existing {
+		if strings.EqualFold(c, add) {
+			return categories
+		}
 	}
+	return append(categories, add)
+}
 
-	return spec.Properties, nil
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeStatusProps merges the supplied user-defined status properties and the
+// supplied status properties Crossplane injects into dst. User-defined
+// properties may not override the injected properties; any that share a name
+// with an injected property are ignored, just as all user-defined status
+// properties once were. The names of the ignored properties are returned.
+func mergeStatusProps(dst, user, injected map[string]extv1.JSONSchemaProps) []string {
+	var ignored []string
+	for k, v := range user {
+		if _, ok := injected[k]; ok {
+			ignored = append(ignored, k)
+			continue
+		}
+		dst[k] = v
+	}
+	for k, v := range injected {
+		dst[k] = v
+	}
+	sort.Strings(ignored)
+	return ignored
 }
 
 // IsEstablished is a helper function to check whether api-server is ready
@@ -216,3 +1252,157 @@ func IsEstablished(s extv1.CustomResourceDefinitionStatus) bool {
 	}
 	return false
 }
+
+// ServedGVKs returns the GroupVersionKinds served by the supplied CRD; one per
+// served version.
+func ServedGVKs(crd *extv1.CustomResourceDefinition) []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0, len(crd.Spec.Versions))
+	for _, vr := range crd.Spec.Versions {
+		if !vr.Served {
+			continue
+		}
+		gvks = append(gvks, schema.GroupVersionKind{Group: crd.Spec.Group, Version: vr.Name, Kind: crd.Spec.Names.Kind})
+	}
+	return gvks
+}
+
+// ClaimGVR returns the GroupVersionResource of the namespaced composite
+// resource claims of the supplied XRD at the supplied version. An error is
+// returned if the XRD does not offer a claim, i.e. its claim names are unset.
+func ClaimGVR(xrd *v1alpha1.CompositeResourceDefinition, version string) (schema.GroupVersionResource, error) {
+	if xrd.Spec.ClaimNames == nil {
+		return schema.GroupVersionResource{}, errors.Wrap(&ClaimNameError{Reason: ClaimNameReasonMissing}, errInvalidClaimNames)
+	}
+	return schema.GroupVersionResource{Group: xrd.Spec.Group, Version: version, Resource: xrd.Spec.ClaimNames.Plural}, nil
+}
+
+// RESTPath returns the path at which api-server serves the custom resources of
+// the supplied CRD at the supplied version, i.e. /apis/<group>/<version>/<plural>.
+// Custom resources of a namespaced CRD are served at this path across all
+// namespaces. An error is returned if the CRD does not serve the version.
+func RESTPath(crd *extv1.CustomResourceDefinition, version string) (string, error) {
+	for _, vr := range crd.Spec.Versions {
+		if vr.Name == version && vr.Served {
+			return fmt.Sprintf("/apis/%s/%s/%s", crd.Spec.Group, version, crd.Spec.Names.Plural), nil
+		}
+	}
+	return "", errors.Errorf(errFmtVersionNotServed, version, crd.GetName())
+}
+
+// UserSpecProps returns the spec properties of the schema of the supplied
+// version of the supplied generated CRD, less those that Crossplane injects.
+// The remaining properties are those of the XRD the CRD was generated from.
+// Namespaced CRDs are assumed to be composite resource claims, and cluster
+// scoped CRDs to be composite resources. An error is returned if the CRD has no
+// such version, or if the version has no schema.
+func UserSpecProps(crd *extv1.CustomResourceDefinition, version string) (map[string]extv1.JSONSchemaProps, error) {
+	var vr *extv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == version {
+			vr = &crd.Spec.Versions[i]
+		}
+	}
+	if vr == nil {
+		return nil, errors.Errorf(errFmtUnknownVersion, crd.GetName(), version)
+	}
+	if vr.Schema == nil || vr.Schema.OpenAPIV3Schema == nil {
+		return nil, errors.Errorf(errFmtNoSchema, version, crd.GetName())
+	}
+
+	injected := injectableSpecProps(crd.Spec.Scope)
+	props := map[string]extv1.JSONSchemaProps{}
+	for k, v := range vr.Schema.OpenAPIV3Schema.Properties["spec"].Properties {
+		if _, ok := injected[k]; !ok {
+			props[k] = v
+		}
+	}
+	return props, nil
+}
+
+// injectableSpecProps returns every spec property Crossplane may inject into a
+// CRD of the supplied scope, including those it injects only optionally.
+func injectableSpecProps(scope extv1.ResourceScope) map[string]extv1.JSONSchemaProps {
+	if scope == extv1.NamespaceScoped {
+		injected := CompositeResourceClaimSpecProps()
+		for k, v := range CompositeDeletePolicyProps() {
+			injected[k] = v
+		}
+		return injected
+	}
+
+	// Optional properties are only ever injected into composite resources.
+	injected := CompositeResourceSpecProps()
+	for _, optional := range []map[string]extv1.JSONSchemaProps{ManagementPoliciesProps(), PausedProps(), EnvironmentConfigRefsProps()} {
+		for k, v := range optional {
+			injected[k] = v
+		}
+	}
+	return injected
+}
+
+// RequiresRecreate returns true if the old CRD cannot be updated to the new
+// CRD, and must instead be deleted and recreated. Deleting a CRD deletes all of
+// its custom resources. A CRD must be recreated if its scope or group changes,
+// or if any version in which its custom resources are or may have been stored
+// is removed.
+func RequiresRecreate(old, new *extv1.CustomResourceDefinition) bool {
+	if old.Spec.Scope != new.Spec.Scope || old.Spec.Group != new.Spec.Group {
+		return true
+	}
+
+	versions := make(map[string]bool, len(new.Spec.Versions))
+	for _, vr := range new.Spec.Versions {
+		versions[vr.Name] = true
+	}
+
+	stored := append([]string{}, old.Status.StoredVersions...)
+	for _, vr := range old.Spec.Versions {
+		if vr.Storage {
+			stored = append(stored, vr.Name)
+		}
+	}
+
+	for _, v := range stored {
+		if !versions[v] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WaitEstablished calls the supplied function at the supplied interval until it
+// returns a CustomResourceDefinitionStatus indicating that api-server is ready
+// to accept instances of the CRD. It returns early if the supplied context is
+// done, if the supplied function returns an error, or if api-server will never
+// establish the CRD because its names were not accepted. An error is returned
+// if the supplied interval is not positive.
+func WaitEstablished(ctx context.Context, get func() (extv1.CustomResourceDefinitionStatus, error), interval time.Duration) error {
+	if interval <= 0 {
+		return errors.Errorf(errFmtInvalidInterval, interval)
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		s, err := get()
+		if err != nil {
+			return errors.Wrap(err, errGetCRDStatus)
+		}
+		if IsEstablished(s) {
+			return nil
+		}
+		for _, c := range s.Conditions {
+			if c.Type == extv1.NamesAccepted && c.Status == extv1.ConditionFalse {
+				return errors.Errorf(errFmtNamesNotAccepted, c.Reason, c.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), errWaitEstablished)
+		case <-t.C:
+		}
+	}
+}
@@ -23,15 +23,24 @@ limitations under the License.
 package ccrd
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
 
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
@@ -68,6 +77,319 @@ func TestIsEstablished(t *testing.T) {
 	}
 }
 
+func TestServedGVKs(t *testing.T) {
+	crd := func(v ...extv1.CustomResourceDefinitionVersion) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group:    "example.org",
+				Names:    extv1.CustomResourceDefinitionNames{Kind: "CoolComposite"},
+				Versions: v,
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		crd    *extv1.CustomResourceDefinition
+		want   []schema.GroupVersionKind
+	}{
+		"NoVersions": {
+			reason: "A CRD with no versions serves no GVKs.",
+			crd:    crd(),
+			want:   []schema.GroupVersionKind{},
+		},
+		"SomeVersionsServed": {
+			reason: "Only served versions should be returned, in the order they are declared.",
+			crd: crd(
+				extv1.CustomResourceDefinitionVersion{Name: "v1alpha1", Served: false},
+				extv1.CustomResourceDefinitionVersion{Name: "v1beta1", Served: true},
+				extv1.CustomResourceDefinitionVersion{Name: "v1", Served: true, Storage: true},
+			),
+			want: []schema.GroupVersionKind{
+				{Group: "example.org", Version: "v1beta1", Kind: "CoolComposite"},
+				{Group: "example.org", Version: "v1", Kind: "CoolComposite"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ServedGVKs(tc.crd)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nServedGVKs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClaimGVR(t *testing.T) {
+	type want struct {
+		gvr schema.GroupVersionResource
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   want
+	}{
+		"ClaimNames": {
+			reason: "The GVR of the claim should be returned when the XRD offers a claim.",
+			xrd:    minimalXRD(`{}`),
+			want:   want{gvr: schema.GroupVersionResource{Group: "example.org", Version: "v1alpha1", Resource: "coolclaims"}},
+		},
+		"NoClaimNames": {
+			reason: "An error should be returned when the XRD does not offer a claim.",
+			xrd: func() *v1alpha1.CompositeResourceDefinition {
+				d := minimalXRD(`{}`)
+				d.Spec.ClaimNames = nil
+				return d
+			}(),
+			want: want{err: errors.Wrap(&ClaimNameError{Reason: ClaimNameReasonMissing}, errInvalidClaimNames)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ClaimGVR(tc.xrd, "v1alpha1")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nClaimGVR(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.gvr, got); diff != "" {
+				t.Errorf("\n%s\nClaimGVR(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRESTPath(t *testing.T) {
+	crd := &extv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "coolcomposites.example.org"},
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "example.org",
+			Names: extv1.CustomResourceDefinitionNames{Plural: "coolcomposites"},
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: false},
+				{Name: "v1", Served: true, Storage: true},
+			},
+		},
+	}
+
+	type want struct {
+		path string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		version string
+		want    want
+	}{
+		"Served": {
+			reason:  "The path of a served version should be returned.",
+			version: "v1",
+			want:    want{path: "/apis/example.org/v1/coolcomposites"},
+		},
+		"NotServed": {
+			reason:  "An error should be returned for a version that is not served.",
+			version: "v1alpha1",
+			want:    want{err: errors.Errorf(errFmtVersionNotServed, "v1alpha1", "coolcomposites.example.org")},
+		},
+		"NotFound": {
+			reason:  "An error should be returned for a version that does not exist.",
+			version: "v2",
+			want:    want{err: errors.Errorf(errFmtVersionNotServed, "v2", "coolcomposites.example.org")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RESTPath(crd, tc.version)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRESTPath(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.path, got); diff != "" {
+				t.Errorf("\n%s\nRESTPath(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUserSpecProps(t *testing.T) {
+	schema := `{"properties":{"spec":{"properties":{"storageGB":{"type":"integer"},"engine":{"type":"string"}},"type":"object"}},"type":"object"}`
+	user := map[string]extv1.JSONSchemaProps{
+		"storageGB": {Type: "integer"},
+		"engine":    {Type: "string"},
+	}
+
+	composite, claim, err := ForXRD(minimalXRD(schema), WithManagementPolicies(), WithPausedField())
+	if err != nil {
+		t.Fatalf("ForXRD(...): %s", err)
+	}
+
+	pausedSchema := `{"properties":{"spec":{"properties":{"paused":{"type":"boolean"}},"type":"object"}},"type":"object"}`
+	pausedClaim, err := ForCompositeResourceClaim(minimalXRD(pausedSchema))
+	if err != nil {
+		t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+	}
+
+	type want struct {
+		props map[string]extv1.JSONSchemaProps
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		crd     *extv1.CustomResourceDefinition
+		version string
+		want    want
+	}{
+		"Composite": {
+			reason:  "Only the user-defined spec properties of a composite resource CRD should be returned.",
+			crd:     composite,
+			version: "v1alpha1",
+			want:    want{props: user},
+		},
+		"Claim": {
+			reason:  "Only the user-defined spec properties of a composite resource claim CRD should be returned.",
+			crd:     claim,
+			version: "v1alpha1",
+			want:    want{props: user},
+		},
+		"ClaimWithOptionalPropName": {
+			reason:  "User-defined claim spec properties named like an optional composite resource property should be returned, because optional properties are never injected into claims.",
+			crd:     pausedClaim,
+			version: "v1alpha1",
+			want:    want{props: map[string]extv1.JSONSchemaProps{"paused": {Type: "boolean"}}},
+		},
+		"UnknownVersion": {
+			reason:  "An error should be returned if the CRD has no such version.",
+			crd:     composite,
+			version: "v1",
+			want:    want{err: errors.Errorf(errFmtUnknownVersion, "coolcomposites.example.org", "v1")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := UserSpecProps(tc.crd, tc.version)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUserSpecProps(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.props, got); diff != "" {
+				t.Errorf("\n%s\nUserSpecProps(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWaitEstablished(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	established := extv1.CustomResourceDefinitionStatus{
+		Conditions: []extv1.CustomResourceDefinitionCondition{{
+			Type:   extv1.Established,
+			Status: extv1.ConditionTrue,
+		}},
+	}
+	pending := extv1.CustomResourceDefinitionStatus{
+		Conditions: []extv1.CustomResourceDefinitionCondition{{
+			Type:   extv1.NamesAccepted,
+			Status: extv1.ConditionTrue,
+		}},
+	}
+	rejected := extv1.CustomResourceDefinitionStatus{
+		Conditions: []extv1.CustomResourceDefinitionCondition{{
+			Type:    extv1.NamesAccepted,
+			Status:  extv1.ConditionFalse,
+			Reason:  "NameConflict",
+			Message: "cool is already in use",
+		}},
+	}
+
+	// sequence returns a get function that returns each of the supplied
+	// statuses in turn, repeating the final status indefinitely.
+	sequence := func(s ...extv1.CustomResourceDefinitionStatus) func() (extv1.CustomResourceDefinitionStatus, error) {
+		i := 0
+		return func() (extv1.CustomResourceDefinitionStatus, error) {
+			got := s[i]
+			if i < len(s)-1 {
+				i++
+			}
+			return got, nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason   string
+		get      func() (extv1.CustomResourceDefinitionStatus, error)
+		interval time.Duration
+		timeout  time.Duration
+		want     error
+	}{
+		"AlreadyEstablished": {
+			reason:   "We should return immediately if the CRD is already established.",
+			get:      sequence(established),
+			interval: time.Millisecond,
+			timeout:  time.Second,
+		},
+		"EventuallyEstablished": {
+			reason:   "We should keep polling until the CRD becomes established.",
+			get:      sequence(pending, pending, established),
+			interval: time.Millisecond,
+			timeout:  time.Second,
+		},
+		"NamesNotAccepted": {
+			reason:   "We should stop polling if the CRD's names are not accepted.",
+			get:      sequence(pending, rejected),
+			interval: time.Millisecond,
+			timeout:  time.Second,
+			want:     errors.Errorf(errFmtNamesNotAccepted, "NameConflict", "cool is already in use"),
+		},
+		"GetError": {
+			reason: "We should stop polling if we cannot get the CRD's status.",
+			get: func() (extv1.CustomResourceDefinitionStatus, error) {
+				return extv1.CustomResourceDefinitionStatus{}, errBoom
+			},
+			interval: time.Millisecond,
+			timeout:  time.Second,
+			want:     errors.Wrap(errBoom, errGetCRDStatus),
+		},
+		"ContextDone": {
+			reason:   "We should stop polling when our context is done.",
+			get:      sequence(pending),
+			interval: time.Millisecond,
+			timeout:  5 * time.Millisecond,
+			want:     errors.Wrap(context.DeadlineExceeded, errWaitEstablished),
+		},
+		"ZeroInterval": {
+			reason:  "We should return an error rather than poll at a non-positive interval.",
+			get:     sequence(pending),
+			timeout: time.Second,
+			want:    errors.Errorf(errFmtInvalidInterval, time.Duration(0)),
+		},
+		"NegativeInterval": {
+			reason:   "We should return an error rather than poll at a non-positive interval.",
+			get:      sequence(pending),
+			interval: -time.Second,
+			timeout:  time.Second,
+			want:     errors.Errorf(errFmtInvalidInterval, -time.Second),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+
+			err := WaitEstablished(ctx, tc.get, tc.interval)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWaitEstablished(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestForCompositeResource(t *testing.T) {
 	name := "coolcomposites.example.org"
 	labels := map[string]string{"cool": "very"}
@@ -110,8 +432,12 @@ func TestForCompositeResource(t *testing.T) {
 
 	want := &extv1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        name,
-			Labels:      labels,
+			Name: name,
+			Labels: map[string]string{
+				"cool":             "very",
+				LabelKeyOwnedByXRD: name,
+				LabelKeyManagedBy:  LabelValueManagedBy,
+			},
 			Annotations: annotations,
 			OwnerReferences: []metav1.OwnerReference{
 				meta.AsController(meta.TypedReferenceTo(d, v1alpha1.CompositeResourceDefinitionGroupVersionKind)),
@@ -252,6 +578,12 @@ func TestForCompositeResource(t *testing.T) {
 											},
 										},
 									},
+									"connectionDetails": {
+										Type: "object",
+										Properties: map[string]extv1.JSONSchemaProps{
+											"lastPublishedTime": {Type: "string", Format: "date-time"},
+										},
+									},
 								},
 							},
 						},
@@ -269,6 +601,198 @@ func TestForCompositeResource(t *testing.T) {
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Errorf("ForCompositeResource(...): -want, +got:\n%s", diff)
 	}
+
+	if diff := cmp.Diff(map[string]string{"cool": "very"}, d.GetLabels()); diff != "" {
+		t.Errorf("ForCompositeResource(...): -want XRD labels, +got XRD labels:\n%s", diff)
+	}
+}
+
+func TestValidateVersions(t *testing.T) {
+	cases := map[string]struct {
+		d    *v1alpha1.CompositeResourceDefinition
+		want error
+	}{
+		"NilVersions": {
+			d:    &v1alpha1.CompositeResourceDefinition{},
+			want: errors.New(errMissingVersions),
+		},
+		"EmptyVersions": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{},
+				},
+			},
+			want: errors.New(errMissingVersions),
+		},
+		"OneVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{{
+						Name:          "v1alpha1",
+						Referenceable: true,
+						Served:        true,
+					}},
+				},
+			},
+			want: nil,
+		},
+		"OneVersionFlagsOmitted": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{{
+						Name: "v1alpha1",
+					}},
+				},
+			},
+			want: errors.New(errNoReferenceableVersion),
+		},
+		"OneVersionNotServed": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{{
+						Name:          "v1alpha1",
+						Referenceable: true,
+					}},
+				},
+			},
+			want: errors.Errorf(errFmtUnservedReferenceable, "v1alpha1"),
+		},
+		"MultipleVersions": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1alpha1"},
+						{Name: "v1beta1", Served: true},
+						{Name: "v1", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: nil,
+		},
+		"MultipleVersionsNoneReferenceable": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1alpha1", Served: true},
+						{Name: "v1beta1", Served: true},
+					},
+				},
+			},
+			want: errors.New(errNoReferenceableVersion),
+		},
+		"MultipleVersionsReferenceable": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1alpha1", Served: true, Referenceable: true},
+						{Name: "v1beta1", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtMultipleReferenceable, "v1alpha1", "v1beta1"),
+		},
+		"DuplicateVersions": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1alpha1", Served: true},
+						{Name: "v1beta1", Served: true, Referenceable: true},
+						{Name: "v1alpha1", Served: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtDuplicateVersion, "v1alpha1"),
+		},
+		"MalformedVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1", Served: true, Referenceable: true},
+						{Name: "v2-beta", Served: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtInvalidVersionName, "v2-beta"),
+		},
+		"UnprefixedVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "1.0", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtInvalidVersionName, "1.0"),
+		},
+		"ZeroVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v0", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtInvalidVersionName, "v0"),
+		},
+		"LeadingZeroVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v01", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtInvalidVersionName, "v01"),
+		},
+		"ZeroPrereleaseVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1alpha0", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtInvalidVersionName, "v1alpha0"),
+		},
+		"LeadingZeroPrereleaseVersion": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					Versions: []v1alpha1.CompositeResourceDefinitionVersion{
+						{Name: "v1beta01", Served: true, Referenceable: true},
+					},
+				},
+			},
+			want: errors.Errorf(errFmtInvalidVersionName, "v1beta01"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateVersions(tc.d)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("validateVersions(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestForCompositeResourceMissingVersions(t *testing.T) {
+	d := &v1alpha1.CompositeResourceDefinition{
+		Spec: v1alpha1.CompositeResourceDefinitionSpec{
+			Group: "example.org",
+			Names: extv1.CustomResourceDefinitionNames{
+				Plural: "coolcomposites",
+				Kind:   "CoolComposite",
+			},
+			Versions: nil,
+		},
+	}
+
+	want := errors.Wrap(errors.New(errMissingVersions), errInvalidVersions)
+	_, err := ForCompositeResource(d)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ForCompositeResource(...): -want error, +got error:\n%s", diff)
+	}
 }
 
 func TestValidateClaimNames(t *testing.T) {
@@ -278,7 +802,7 @@ func TestValidateClaimNames(t *testing.T) {
 	}{
 		"MissingClaimNames": {
 			d:    &v1alpha1.CompositeResourceDefinition{},
-			want: errors.New(errMissingClaimNames),
+			want: &ClaimNameError{Reason: ClaimNameReasonMissing},
 		},
 		"KindConflict": {
 			d: &v1alpha1.CompositeResourceDefinition{
@@ -297,7 +821,7 @@ func TestValidateClaimNames(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Errorf(errFmtConflictingClaimName, "a"),
+			want: conflictingClaimName("kind", "a"),
 		},
 		"ListKindConflict": {
 			d: &v1alpha1.CompositeResourceDefinition{
@@ -316,7 +840,7 @@ func TestValidateClaimNames(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Errorf(errFmtConflictingClaimName, "a"),
+			want: conflictingClaimName("listKind", "a"),
 		},
 		"SingularConflict": {
 			d: &v1alpha1.CompositeResourceDefinition{
@@ -335,7 +859,7 @@ func TestValidateClaimNames(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Errorf(errFmtConflictingClaimName, "a"),
+			want: conflictingClaimName("singular", "a"),
 		},
 		"PluralConflict": {
 			d: &v1alpha1.CompositeResourceDefinition{
@@ -355,38 +879,220 @@ func TestValidateClaimNames(t *testing.T) {
 					},
 				},
 			},
-			want: errors.Errorf(errFmtConflictingClaimName, "a"),
+			want: conflictingClaimName("plural", "a"),
 		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			got := validateClaimNames(tc.d)
-			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
-				t.Errorf("validateClaimNames(...): -want, +got:\n%s", diff)
-			}
-		})
-	}
-}
-
-func TestForCompositeResourceClaim(t *testing.T) {
-	name := "coolcomposites.example.org"
-	labels := map[string]string{"cool": "very"}
-	annotations := map[string]string{"example.org/cool": "very"}
-
-	group := "example.org"
-	version := "v1alpha1"
-
-	kind := "CoolComposite"
-	listKind := "CoolCompositeList"
-	singular := "coolcomposite"
-	plural := "coolcomposites"
-
-	claimKind := "CoolClaim"
-	claimListKind := "CoolClaimList"
-	claimSingular := "coolclaim"
-	claimPlural := "coolclaims"
-
+		"ClaimKindListKindConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:     "a",
+						ListKind: "aList",
+						Singular: "a",
+						Plural:   "a",
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:     "b",
+						ListKind: "a",
+						Singular: "b",
+						Plural:   "b",
+					},
+				},
+			},
+			want: conflictingClaimName("kind", "a"),
+		},
+		"ClaimListKindKindConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:     "a",
+						ListKind: "b",
+						Singular: "a",
+						Plural:   "a",
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:     "b",
+						ListKind: "bList",
+						Singular: "b",
+						Plural:   "b",
+					},
+				},
+			},
+			want: conflictingClaimName("listKind", "b"),
+		},
+		"ClaimKindDefaultListKindConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:     "bList",
+						Singular: "a",
+						Plural:   "a",
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:     "b",
+						Singular: "b",
+						Plural:   "b",
+					},
+				},
+			},
+			want: conflictingClaimName("kind", "bList"),
+		},
+		"ShortNameConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:       "a",
+						Plural:     "a",
+						ShortNames: []string{"x", "db"},
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:       "b",
+						Plural:     "b",
+						ShortNames: []string{"db"},
+					},
+				},
+			},
+			want: conflictingClaimName("shortNames", "db"),
+		},
+		"ClaimShortNamePluralConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:       "a",
+						Plural:     "a",
+						ShortNames: []string{"b"},
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:   "b",
+						Plural: "b",
+					},
+				},
+			},
+			want: conflictingClaimName("shortNames", "b"),
+		},
+		"CompositeShortNameSingularConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:     "A",
+						Singular: "a",
+						Plural:   "as",
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:       "B",
+						Plural:     "bs",
+						ShortNames: []string{"a"},
+					},
+				},
+			},
+			want: conflictingClaimName("shortNames", "a"),
+		},
+		"DistinctShortNames": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:       "a",
+						Plural:     "a",
+						ShortNames: []string{"db"},
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:       "b",
+						Plural:     "b",
+						ShortNames: []string{"xdb"},
+					},
+				},
+			},
+			want: nil,
+		},
+		"NoConflict": {
+			d: &v1alpha1.CompositeResourceDefinition{
+				Spec: v1alpha1.CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:   "a",
+						Plural: "a",
+					},
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:   "b",
+						Plural: "b",
+					},
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateClaimNames(tc.d)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("validateClaimNames(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClaimNameError(t *testing.T) {
+	missing := minimalXRD(`{}`)
+	missing.Spec.ClaimNames = nil
+
+	conflicting := minimalXRD(`{}`)
+	conflicting.Spec.ClaimNames.Plural = conflicting.Spec.Names.Plural
+
+	cases := map[string]struct {
+		reason string
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   *ClaimNameError
+		msg    string
+	}{
+		"Missing": {
+			reason: "Callers should be able to determine that claim names are missing.",
+			xrd:    missing,
+			want:   &ClaimNameError{Reason: ClaimNameReasonMissing},
+			msg:    errInvalidClaimNames + ": " + errMissingClaimNames,
+		},
+		"Conflicting": {
+			reason: "Callers should be able to determine which claim name conflicts.",
+			xrd:    conflicting,
+			want:   &ClaimNameError{Field: "plural", Value: "coolcomposites", Reason: ClaimNameReasonConflicting},
+			msg:    errInvalidClaimNames + ": \"coolcomposites\" conflicts with composite resource name",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ForCompositeResourceClaim(tc.xrd)
+
+			var got *ClaimNameError
+			if !errors.As(err, &got) {
+				t.Fatalf("\n%s\nForCompositeResourceClaim(...): want *ClaimNameError, got %T", tc.reason, errors.Cause(err))
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.msg, err.Error()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want message, +got message:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestForCompositeResourceClaim(t *testing.T) {
+	name := "coolcomposites.example.org"
+	labels := map[string]string{"cool": "very"}
+	annotations := map[string]string{"example.org/cool": "very"}
+
+	group := "example.org"
+	version := "v1alpha1"
+
+	kind := "CoolComposite"
+	listKind := "CoolCompositeList"
+	singular := "coolcomposite"
+	plural := "coolcomposites"
+
+	claimKind := "CoolClaim"
+	claimListKind := "CoolClaimList"
+	claimSingular := "coolclaim"
+	claimPlural := "coolclaims"
+
 	schema := `{"properties":{"spec":{"properties":{"engineVersion":{"enum":["5.6","5.7"],"type":"string"},"storageGB":{"type":"integer"}},"type":"object"}},"type":"object"}`
 
 	d := &v1alpha1.CompositeResourceDefinition{
@@ -423,8 +1129,12 @@ func TestForCompositeResourceClaim(t *testing.T) {
 
 	want := &extv1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        claimPlural + "." + group,
-			Labels:      labels,
+			Name: claimPlural + "." + group,
+			Labels: map[string]string{
+				"cool":             "very",
+				LabelKeyOwnedByXRD: name,
+				LabelKeyManagedBy:  LabelValueManagedBy,
+			},
 			Annotations: annotations,
 			OwnerReferences: []metav1.OwnerReference{
 				meta.AsController(meta.TypedReferenceTo(d, v1alpha1.CompositeResourceDefinitionGroupVersionKind)),
@@ -549,6 +1259,12 @@ func TestForCompositeResourceClaim(t *testing.T) {
 												},
 											},
 										},
+										"connectionDetails": {
+											Type: "object",
+											Properties: map[string]extv1.JSONSchemaProps{
+												"lastPublishedTime": {Type: "string", Format: "date-time"},
+											},
+										},
 									},
 								},
 							},
@@ -567,4 +1283,1944 @@ func TestForCompositeResourceClaim(t *testing.T) {
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Errorf("ForCompositeResourceClaim(...): -want, +got:\n%s", diff)
 	}
+
+	if diff := cmp.Diff(map[string]string{"cool": "very"}, d.GetLabels()); diff != "" {
+		t.Errorf("ForCompositeResourceClaim(...): -want XRD labels, +got XRD labels:\n%s", diff)
+	}
+}
+
+func TestMergeStatusProps(t *testing.T) {
+	type want struct {
+		dst     map[string]extv1.JSONSchemaProps
+		ignored []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		user   map[string]extv1.JSONSchemaProps
+		want   want
+	}{
+		"NoUserProps": {
+			reason: "Only the injected status properties should be present if the user supplies none.",
+			want: want{
+				dst: CompositeResourceStatusProps(),
+			},
+		},
+		"UserProps": {
+			reason: "User-defined status properties should be merged alongside the injected status properties.",
+			user: map[string]extv1.JSONSchemaProps{
+				"phase": {Type: "string"},
+			},
+			want: want{
+				dst: func() map[string]extv1.JSONSchemaProps {
+					p := CompositeResourceStatusProps()
+					p["phase"] = extv1.JSONSchemaProps{Type: "string"}
+					return p
+				}(),
+			},
+		},
+		"ConditionsConflict": {
+			reason: "A user-defined status property may not override the injected conditions property, and should be ignored.",
+			user: map[string]extv1.JSONSchemaProps{
+				"conditions": {Type: "string"},
+			},
+			want: want{
+				dst:     CompositeResourceStatusProps(),
+				ignored: []string{"conditions"},
+			},
+		},
+		"ConnectionDetailsConflict": {
+			reason: "A user-defined status property may not override the injected connectionDetails property, and should be ignored.",
+			user: map[string]extv1.JSONSchemaProps{
+				"connectionDetails": {Type: "string"},
+				"phase":             {Type: "string"},
+			},
+			want: want{
+				dst: func() map[string]extv1.JSONSchemaProps {
+					p := CompositeResourceStatusProps()
+					p["phase"] = extv1.JSONSchemaProps{Type: "string"}
+					return p
+				}(),
+				ignored: []string{"connectionDetails"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dst := map[string]extv1.JSONSchemaProps{}
+			ignored := mergeStatusProps(dst, tc.user, CompositeResourceStatusProps())
+			if diff := cmp.Diff(tc.want.ignored, ignored); diff != "" {
+				t.Errorf("\n%s\nmergeStatusProps(...): -want ignored, +got ignored:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.dst, dst); diff != "" {
+				t.Errorf("\n%s\nmergeStatusProps(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// minimalXRD returns a valid CompositeResourceDefinition that offers a claim
+// and has a single version with the supplied schema.
+func minimalXRD(schema string) *v1alpha1.CompositeResourceDefinition {
+	return &v1alpha1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "coolcomposites.example.org"},
+		Spec: v1alpha1.CompositeResourceDefinitionSpec{
+			Group: "example.org",
+			Names: extv1.CustomResourceDefinitionNames{
+				Plural: "coolcomposites",
+				Kind:   "CoolComposite",
+			},
+			ClaimNames: &extv1.CustomResourceDefinitionNames{
+				Plural: "coolclaims",
+				Kind:   "CoolClaim",
+			},
+			Versions: []v1alpha1.CompositeResourceDefinitionVersion{{
+				Name:          "v1alpha1",
+				Referenceable: true,
+				Served:        true,
+				Schema: &v1alpha1.CompositeResourceValidation{
+					OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(schema)},
+				},
+			}},
+		},
+	}
+}
+
+func TestForCompositeResources(t *testing.T) {
+	xrd := func(plural string) *v1alpha1.CompositeResourceDefinition {
+		d := minimalXRD(`{}`)
+		d.SetName(plural + ".example.org")
+		d.Spec.Names.Plural = plural
+		return d
+	}
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err     error
+		emitted []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		xrds   []*v1alpha1.CompositeResourceDefinition
+		emit   func(*extv1.CustomResourceDefinition) error
+		want   want
+	}{
+		"Empty": {
+			reason: "Nothing should be emitted when no XRDs are supplied.",
+			want:   want{},
+		},
+		"Success": {
+			reason: "A CRD should be emitted for each XRD, in order.",
+			xrds:   []*v1alpha1.CompositeResourceDefinition{xrd("first"), xrd("second")},
+			want:   want{emitted: []string{"first.example.org", "second.example.org"}},
+		},
+		"GenerateError": {
+			reason: "Generation should stop at the first XRD that cannot be generated.",
+			xrds:   []*v1alpha1.CompositeResourceDefinition{xrd("first"), xrd("Second"), xrd("third")},
+			want: want{
+				err:     errors.Wrapf(errors.Wrap(ValidateNames(xrd("Second").Spec.Names), errInvalidNames), errFmtGenerateXRD, "Second.example.org"),
+				emitted: []string{"first.example.org"},
+			},
+		},
+		"EmitError": {
+			reason: "Generation should stop at the first CRD that cannot be emitted.",
+			xrds:   []*v1alpha1.CompositeResourceDefinition{xrd("first"), xrd("second")},
+			emit:   func(_ *extv1.CustomResourceDefinition) error { return errBoom },
+			want: want{
+				err:     errors.Wrapf(errBoom, errFmtEmitXRD, "first.example.org"),
+				emitted: []string{"first.example.org"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var emitted []string
+			emit := func(crd *extv1.CustomResourceDefinition) error {
+				emitted = append(emitted, crd.GetName())
+				if tc.emit != nil {
+					return tc.emit(crd)
+				}
+				return nil
+			}
+
+			err := ForCompositeResources(tc.xrds, emit)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResources(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.emitted, emitted); diff != "" {
+				t.Errorf("\n%s\nForCompositeResources(...): -want emitted, +got emitted:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStatusPropsConflict(t *testing.T) {
+	d := minimalXRD(`{"properties":{"status":{"properties":{"conditions":{"type":"string"}},"type":"object"}},"type":"object"}`)
+
+	want := CompositeResourceStatusProps()["conditions"]
+
+	xr, err := ForCompositeResource(d)
+	if err != nil {
+		t.Fatalf("ForCompositeResource(...): %s", err)
+	}
+	if diff := cmp.Diff(want, xr.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["status"].Properties["conditions"]); diff != "" {
+		t.Errorf("ForCompositeResource(...): -want, +got:\n%s", diff)
+	}
+
+	xrc, err := ForCompositeResourceClaim(d)
+	if err != nil {
+		t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+	}
+	if diff := cmp.Diff(want, xrc.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["status"].Properties["conditions"]); diff != "" {
+		t.Errorf("ForCompositeResourceClaim(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestWithAllowedCompositions(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   extv1.JSONSchemaProps
+	}{
+		"Unconstrained": {
+			reason: "The compositionRef name should not be constrained by default.",
+			want:   extv1.JSONSchemaProps{Type: "string"},
+		},
+		"EmptyAllowList": {
+			reason: "The compositionRef name should not be constrained when no compositions are allowed.",
+			opts:   []Option{WithAllowedCompositions()},
+			want:   extv1.JSONSchemaProps{Type: "string"},
+		},
+		"Constrained": {
+			reason: "The compositionRef name should be constrained to the allowed compositions.",
+			opts:   []Option{WithAllowedCompositions("cool", "cooler")},
+			want: extv1.JSONSchemaProps{
+				Type: "string",
+				Enum: []extv1.JSON{
+					{Raw: []byte(`"cool"`)},
+					{Raw: []byte(`"cooler"`)},
+				},
+			},
+		},
+		"RepeatedOption": {
+			reason: "Compositions allowed by repeated uses of the option should all be allowed, once each.",
+			opts:   []Option{WithAllowedCompositions("cool"), WithAllowedCompositions("cooler", "cool")},
+			want: extv1.JSONSchemaProps{
+				Type: "string",
+				Enum: []extv1.JSON{
+					{Raw: []byte(`"cool"`)},
+					{Raw: []byte(`"cooler"`)},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResource(minimalXRD(`{}`), tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			got := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["compositionRef"].Properties["name"]
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithDefaultComposition(t *testing.T) {
+	type want struct {
+		annotation string
+		err        bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   want
+	}{
+		"NotDefaulted": {
+			reason: "The default composition should not be annotated by default.",
+			want:   want{},
+		},
+		"Defaulted": {
+			reason: "The default composition should be annotated.",
+			opts:   []Option{WithDefaultComposition("cool")},
+			want:   want{annotation: "cool"},
+		},
+		"DefaultedAndAllowed": {
+			reason: "The default composition may be one of the allowed compositions.",
+			opts:   []Option{WithAllowedCompositions("cool", "cooler"), WithDefaultComposition("cooler")},
+			want:   want{annotation: "cooler"},
+		},
+		"InvalidName": {
+			reason: "An error should be returned if the default composition is not a valid DNS name.",
+			opts:   []Option{WithDefaultComposition("Cool_Composition")},
+			want:   want{err: true},
+		},
+		"NotAllowed": {
+			reason: "An error should be returned if the default composition is not an allowed composition.",
+			opts:   []Option{WithAllowedCompositions("cool"), WithDefaultComposition("cooler")},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+			d.SetAnnotations(map[string]string{"example.org/cool": "true"})
+
+			crd, err := ForCompositeResource(d, tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Fatalf("\n%s\nForCompositeResource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.annotation, crd.GetAnnotations()[AnnotationKeyDefaultComposition]); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if _, ok := d.GetAnnotations()[AnnotationKeyDefaultComposition]; ok {
+				t.Errorf("\n%s\nForCompositeResource(...): the XRD's annotations should not be modified", tc.reason)
+			}
+
+			// A schema default would also apply to composite resources that
+			// specify a compositionSelector, overriding their selector.
+			if got := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["compositionRef"].Default; got != nil {
+				t.Errorf("\n%s\nForCompositeResource(...): compositionRef should not have a schema default, got %s", tc.reason, got.Raw)
+			}
+
+			claim, err := ForCompositeResourceClaim(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			if _, ok := claim.GetAnnotations()[AnnotationKeyDefaultComposition]; ok {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): claims should not be annotated with a default composition", tc.reason)
+			}
+		})
+	}
+}
+
+func TestWithBlockOwnerDeletion(t *testing.T) {
+	block := true
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   *bool
+	}{
+		"Default": {
+			reason: "Owner deletion should not be blocked by default.",
+			want:   nil,
+		},
+		"Blocked": {
+			reason: "Owner deletion should be blocked when requested.",
+			opts:   []Option{WithBlockOwnerDeletion()},
+			want:   &block,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+
+			xr, err := ForCompositeResource(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xr.GetOwnerReferences()[0].BlockOwnerDeletion); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			xrc, err := ForCompositeResourceClaim(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xrc.GetOwnerReferences()[0].BlockOwnerDeletion); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOptionalSpecProps(t *testing.T) {
+	userSchema := func(prop string) string {
+		return fmt.Sprintf(`{"properties":{"spec":{"properties":{%q:{"type":"string"}},"type":"object"}},"type":"object"}`, prop)
+	}
+	user := &extv1.JSONSchemaProps{Type: "string"}
+	injected := func(props map[string]extv1.JSONSchemaProps, k string) *extv1.JSONSchemaProps {
+		p := props[k]
+		return &p
+	}
+	specProp := func(crd *extv1.CustomResourceDefinition, k string) *extv1.JSONSchemaProps {
+		p, ok := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties[k]
+		if !ok {
+			return nil
+		}
+		return &p
+	}
+
+	type want struct {
+		composite *extv1.JSONSchemaProps
+		claim     *extv1.JSONSchemaProps
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		opts   []Option
+		prop   string
+		want   want
+	}{
+		"PausedDisabled": {
+			reason: "The paused field should not be injected by default.",
+			schema: `{}`,
+			prop:   "paused",
+			want:   want{},
+		},
+		"PausedEnabled": {
+			reason: "The paused field should be injected into the composite resource, but not the claim, when enabled.",
+			schema: `{}`,
+			opts:   []Option{WithPausedField()},
+			prop:   "paused",
+			want:   want{composite: injected(PausedProps(), "paused")},
+		},
+		"PausedConflict": {
+			reason: "A user-defined paused property should be rejected for the composite resource, but not the claim, when the paused field is enabled.",
+			schema: userSchema("paused"),
+			opts:   []Option{WithPausedField()},
+			prop:   "paused",
+			want: want{
+				claim: user,
+				err:   errors.Errorf(errFmtInjectedSpecProp, "paused"),
+			},
+		},
+		"ManagementPoliciesDisabled": {
+			reason: "The managementPolicies field should not be injected by default.",
+			schema: `{}`,
+			prop:   "managementPolicies",
+			want:   want{},
+		},
+		"ManagementPoliciesEnabled": {
+			reason: "The managementPolicies field should be injected into the composite resource, but not the claim, when enabled.",
+			schema: `{}`,
+			opts:   []Option{WithManagementPolicies()},
+			prop:   "managementPolicies",
+			want:   want{composite: injected(ManagementPoliciesProps(), "managementPolicies")},
+		},
+		"ManagementPoliciesConflict": {
+			reason: "A user-defined managementPolicies property should be rejected for the composite resource, but not the claim, when the managementPolicies field is enabled.",
+			schema: userSchema("managementPolicies"),
+			opts:   []Option{WithManagementPolicies()},
+			prop:   "managementPolicies",
+			want: want{
+				claim: user,
+				err:   errors.Errorf(errFmtInjectedSpecProp, "managementPolicies"),
+			},
+		},
+		"EnvironmentConfigRefsDisabled": {
+			reason: "The environmentConfigRefs field should not be injected by default.",
+			schema: `{}`,
+			prop:   "environmentConfigRefs",
+			want:   want{},
+		},
+		"EnvironmentConfigRefsEnabled": {
+			reason: "The environmentConfigRefs field should be injected into the composite resource, but not the claim, when enabled.",
+			schema: `{}`,
+			opts:   []Option{WithEnvironmentConfigRefs()},
+			prop:   "environmentConfigRefs",
+			want:   want{composite: injected(EnvironmentConfigRefsProps(), "environmentConfigRefs")},
+		},
+		"EnvironmentConfigRefsConflict": {
+			reason: "A user-defined environmentConfigRefs property should be rejected for the composite resource, but not the claim, when the environmentConfigRefs field is enabled.",
+			schema: userSchema("environmentConfigRefs"),
+			opts:   []Option{WithEnvironmentConfigRefs()},
+			prop:   "environmentConfigRefs",
+			want: want{
+				claim: user,
+				err:   errors.Errorf(errFmtInjectedSpecProp, "environmentConfigRefs"),
+			},
+		},
+		"UserDefinedDisabled": {
+			reason: "A user-defined property named like an optional field should be preserved when that field is not enabled.",
+			schema: userSchema("paused"),
+			opts:   []Option{WithManagementPolicies(), WithEnvironmentConfigRefs()},
+			prop:   "paused",
+			want:   want{composite: user, claim: user},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			composite, err := ForCompositeResource(minimalXRD(tc.schema), tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(tc.want.composite, specProp(composite, tc.prop)); diff != "" {
+					t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+				}
+			}
+
+			claim, err := ForCompositeResourceClaim(minimalXRD(tc.schema), tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.claim, specProp(claim, tc.prop)); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithFinalizers(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   []string
+	}{
+		"Default": {
+			reason: "No finalizers should be added by default.",
+			want:   nil,
+		},
+		"Finalizer": {
+			reason: "The supplied finalizer should be added.",
+			opts:   []Option{WithFinalizers("example.org/protect")},
+			want:   []string{"example.org/protect"},
+		},
+		"Idempotent": {
+			reason: "A finalizer that is supplied more than once should only be added once.",
+			opts:   []Option{WithFinalizers("example.org/protect", "example.org/protect"), WithFinalizers("example.org/protect")},
+			want:   []string{"example.org/protect"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+
+			xr, err := ForCompositeResource(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xr.GetFinalizers()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			xrc, err := ForCompositeResourceClaim(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xrc.GetFinalizers()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRemoveFinalizer(t *testing.T) {
+	type want struct {
+		removed    bool
+		finalizers []string
+	}
+
+	cases := map[string]struct {
+		reason     string
+		finalizers []string
+		want       want
+	}{
+		"Present": {
+			reason:     "The finalizer should be removed if it is present.",
+			finalizers: []string{"example.org/protect", "example.org/other"},
+			want:       want{removed: true, finalizers: []string{"example.org/other"}},
+		},
+		"Absent": {
+			reason:     "Nothing should be removed if the finalizer is absent.",
+			finalizers: []string{"example.org/other"},
+			want:       want{removed: false, finalizers: []string{"example.org/other"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd := &extv1.CustomResourceDefinition{}
+			crd.SetFinalizers(tc.finalizers)
+			removed := RemoveFinalizer(crd, "example.org/protect")
+			if diff := cmp.Diff(tc.want.removed, removed); diff != "" {
+				t.Errorf("\n%s\nRemoveFinalizer(...): -want removed, +got removed:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.finalizers, crd.GetFinalizers()); diff != "" {
+				t.Errorf("\n%s\nRemoveFinalizer(...): -want finalizers, +got finalizers:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithObservedGeneration(t *testing.T) {
+	type want struct {
+		injected bool
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		opts   []Option
+		want   want
+	}{
+		"Disabled": {
+			reason: "The observedGeneration field should not be injected by default.",
+			schema: `{}`,
+		},
+		"Enabled": {
+			reason: "The observedGeneration field should be injected when enabled.",
+			schema: `{}`,
+			opts:   []Option{WithObservedGeneration()},
+			want:   want{injected: true},
+		},
+		"UserDefinedWhenDisabled": {
+			reason: "A user may define an observedGeneration status field when it is not injected.",
+			schema: `{"properties":{"status":{"properties":{"observedGeneration":{"type":"string"}},"type":"object"}},"type":"object"}`,
+			want:   want{injected: false},
+		},
+		"Conflict": {
+			reason: "A user-defined status field may not override the injected observedGeneration field.",
+			schema: `{"properties":{"status":{"properties":{"observedGeneration":{"type":"string"}},"type":"object"}},"type":"object"}`,
+			opts:   []Option{WithObservedGeneration()},
+			want:   want{injected: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResource(minimalXRD(tc.schema), tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nForCompositeResource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			got := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["status"].Properties["observedGeneration"]
+			injected := cmp.Equal(ObservedGenerationProps()["observedGeneration"], got)
+			if diff := cmp.Diff(tc.want.injected, injected); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want injected, +got injected:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithRequiredConnectionSecret(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		schema string
+		opts   []Option
+		want   []string
+	}{
+		"Optional": {
+			reason: "The writeConnectionSecretToRef of a claim should be optional by default.",
+			schema: `{}`,
+			want:   nil,
+		},
+		"Required": {
+			reason: "The writeConnectionSecretToRef of a claim should be required when the option is supplied.",
+			schema: `{}`,
+			opts:   []Option{WithRequiredConnectionSecret()},
+			want:   []string{"writeConnectionSecretToRef"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResourceClaim(minimalXRD(tc.schema), tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			got := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Required
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want required, +got required:\n%s", tc.reason, diff)
+			}
+
+			xr, err := ForCompositeResource(minimalXRD(tc.schema), tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if got := xr.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Required; got != nil {
+				t.Errorf("\n%s\nForCompositeResource(...): composite spec should not require any fields, got %v", tc.reason, got)
+			}
+		})
+	}
+}
+
+func TestSpecNotObject(t *testing.T) {
+	d := minimalXRD(`{"properties":{"spec":{"type":"array","items":{"type":"string"}}},"type":"object"}`)
+
+	want := errors.Errorf(errFmtSpecNotObject, "array")
+
+	_, err := ForCompositeResource(d)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ForCompositeResource(...): -want error, +got error:\n%s", diff)
+	}
+
+	_, err = ForCompositeResourceClaim(d)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ForCompositeResourceClaim(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestReservedSpecProps(t *testing.T) {
+	for _, k := range []string{"apiVersion", "kind", "metadata", "status"} {
+		t.Run(k, func(t *testing.T) {
+			d := minimalXRD(fmt.Sprintf(`{"properties":{"spec":{"properties":{%q:{"type":"object"}},"type":"object"}},"type":"object"}`, k))
+
+			want := errors.Errorf(errFmtReservedSpecProp, k)
+
+			_, err := ForCompositeResource(d)
+			if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ForCompositeResource(...): -want error, +got error:\n%s", diff)
+			}
+
+			_, err = ForCompositeResourceClaim(d)
+			if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ForCompositeResourceClaim(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTitlesPreserved(t *testing.T) {
+	d := minimalXRD(`{"properties":{"spec":{"title":"Cool Spec","properties":{"engineVersion":{"type":"string","title":"Engine Version"}},"type":"object"}},"type":"object"}`)
+
+	want := struct {
+		spec string
+		prop string
+	}{spec: "Cool Spec", prop: "Engine Version"}
+
+	xr, err := ForCompositeResource(d)
+	if err != nil {
+		t.Fatalf("ForCompositeResource(...): %s", err)
+	}
+	xrc, err := ForCompositeResourceClaim(d)
+	if err != nil {
+		t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+	}
+
+	for fn, crd := range map[string]*extv1.CustomResourceDefinition{"ForCompositeResource": xr, "ForCompositeResourceClaim": xrc} {
+		spec := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+		if diff := cmp.Diff(want.spec, spec.Title); diff != "" {
+			t.Errorf("%s(...): -want spec title, +got spec title:\n%s", fn, diff)
+		}
+		if diff := cmp.Diff(want.prop, spec.Properties["engineVersion"].Title); diff != "" {
+			t.Errorf("%s(...): -want property title, +got property title:\n%s", fn, diff)
+		}
+	}
+}
+
+func TestWithTerminatingColumn(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   []extv1.CustomResourceColumnDefinition
+	}{
+		"Disabled": {
+			reason: "Only the default printer columns should be injected by default.",
+			want:   CompositeResourcePrinterColumns(),
+		},
+		"Enabled": {
+			reason: "The TERMINATING printer column should follow the default printer columns when enabled.",
+			opts:   []Option{WithTerminatingColumn()},
+			want:   append(CompositeResourcePrinterColumns(), TerminatingPrinterColumns()...),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResource(minimalXRD(`{}`), tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, crd.Spec.Versions[0].AdditionalPrinterColumns); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithClaimPrinterColumns(t *testing.T) {
+	type want struct {
+		cols []extv1.CustomResourceColumnDefinition
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   want
+	}{
+		"Default": {
+			reason: "Only the default claim printer columns should be injected by default.",
+			want:   want{cols: CompositeResourceClaimPrinterColumns()},
+		},
+		"Included": {
+			reason: "Selected composite printer columns should follow the default claim printer columns.",
+			opts:   []Option{WithTerminatingColumn(), WithClaimPrinterColumns("TERMINATING", "COMPOSITION")},
+			want: want{cols: append(CompositeResourceClaimPrinterColumns(),
+				TerminatingPrinterColumns()[0],
+				CompositeResourcePrinterColumns()[1],
+			)},
+		},
+		"RepeatedOption": {
+			reason: "Columns supplied by repeated uses of the option should all be added, once each.",
+			opts: []Option{
+				WithTerminatingColumn(),
+				WithClaimPrinterColumns("TERMINATING"),
+				WithClaimPrinterColumns("COMPOSITION", "TERMINATING"),
+			},
+			want: want{cols: append(CompositeResourceClaimPrinterColumns(),
+				TerminatingPrinterColumns()[0],
+				CompositeResourcePrinterColumns()[1],
+			)},
+		},
+		"AlreadyIncluded": {
+			reason: "Composite printer columns that claims already include should not be added again.",
+			opts:   []Option{WithClaimPrinterColumns("READY")},
+			want:   want{cols: CompositeResourceClaimPrinterColumns()},
+		},
+		"Unknown": {
+			reason: "An error should be returned if a selected column is not a composite printer column.",
+			opts:   []Option{WithClaimPrinterColumns("TERMINATING")},
+			want:   want{err: errors.Errorf(errFmtUnknownPrinterColumn, "TERMINATING")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResourceClaim(minimalXRD(`{}`), tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nForCompositeResourceClaim(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.cols, crd.Spec.Versions[0].AdditionalPrinterColumns); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithoutCompositeCategory(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   []string
+	}{
+		"Default": {
+			reason: "Composite resources should be in the composite category by default.",
+			want:   []string{"cool", CategoryComposite},
+		},
+		"Omitted": {
+			reason: "Composite resources should not be in the composite category when it is omitted.",
+			opts:   []Option{WithoutCompositeCategory()},
+			want:   []string{"cool"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+			d.Spec.Names.Categories = []string{"cool"}
+
+			crd, err := ForCompositeResource(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, crd.Spec.Names.Categories); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAppendCategory(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		existing []string
+		add      string
+		want     []string
+	}{
+		"NoCategories": {
+			reason: "The category should be added when there are no categories.",
+			add:    CategoryComposite,
+			want:   []string{CategoryComposite},
+		},
+		"Fresh": {
+			reason:   "The category should be appended after the existing categories.",
+			existing: []string{"cool", "neat"},
+			add:      CategoryComposite,
+			want:     []string{"cool", "neat", CategoryComposite},
+		},
+		"Duplicate": {
+			reason:   "The category should not be added if it is already present.",
+			existing: []string{CategoryClaim, "cool"},
+			add:      CategoryClaim,
+			want:     []string{CategoryClaim, "cool"},
+		},
+		"DuplicateDifferentCase": {
+			reason:   "The category should not be added if it is already present with a different case.",
+			existing: []string{"cool", "Composite"},
+			add:      CategoryComposite,
+			want:     []string{"cool", "Composite"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := appendCategory(tc.existing, tc.add)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nappendCategory(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithDefaultCategories(t *testing.T) {
+	type want struct {
+		composite []string
+		claim     []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   want
+	}{
+		"NoDefaults": {
+			reason: "Only the XRD's categories and the built-in categories should be added by default.",
+			want: want{
+				composite: []string{"cool", CategoryComposite},
+				claim:     []string{"cool", CategoryClaim},
+			},
+		},
+		"Defaults": {
+			reason: "Default categories should follow the built-in categories, without duplicates.",
+			opts:   []Option{WithDefaultCategories("acme", "cool")},
+			want: want{
+				composite: []string{"cool", CategoryComposite, "acme"},
+				claim:     []string{"cool", CategoryClaim, "acme"},
+			},
+		},
+		"RepeatedOption": {
+			reason: "Categories supplied by repeated uses of the option should all be added, without duplicates.",
+			opts:   []Option{WithDefaultCategories("acme"), WithDefaultCategories("platform", "ACME")},
+			want: want{
+				composite: []string{"cool", CategoryComposite, "acme", "platform"},
+				claim:     []string{"cool", CategoryClaim, "acme", "platform"},
+			},
+		},
+		"DefaultsWithoutCompositeCategory": {
+			reason: "Default categories should be added even when the composite category is omitted.",
+			opts:   []Option{WithDefaultCategories("acme"), WithoutCompositeCategory()},
+			want: want{
+				composite: []string{"cool", "acme"},
+				claim:     []string{"cool", CategoryClaim, "acme"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+			d.Spec.Names.Categories = []string{"cool"}
+			d.Spec.ClaimNames.Categories = []string{"cool"}
+
+			composite, claim, err := ForXRD(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForXRD(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want.composite, composite.Spec.Names.Categories); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.claim, claim.Spec.Names.Categories); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// A logEntry is a message logged by a fakeLogger, along with its key value
+// pairs (including those of the logger).
+type logEntry struct {
+	Message       string
+	KeysAndValues []interface{}
+}
+
+// A fakeLogger records the debug messages logged to it and its descendants.
+type fakeLogger struct {
+	entries *[]logEntry
+	values  []interface{}
+}
+
+func (l fakeLogger) Info(msg string, keysAndValues ...interface{}) {}
+
+func (l fakeLogger) Debug(msg string, keysAndValues ...interface{}) {
+	*l.entries = append(*l.entries, logEntry{Message: msg, KeysAndValues: append(append([]interface{}{}, l.values...), keysAndValues...)})
+}
+
+func (l fakeLogger) WithValues(keysAndValues ...interface{}) logging.Logger {
+	return fakeLogger{entries: l.entries, values: append(append([]interface{}{}, l.values...), keysAndValues...)}
+}
+
+func TestWithLogger(t *testing.T) {
+	invalid := minimalXRD(`{}`)
+	invalid.Spec.Versions = nil
+
+	cases := map[string]struct {
+		reason string
+		fn     func(xrd *v1alpha1.CompositeResourceDefinition, o ...Option) (*extv1.CustomResourceDefinition, error)
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   []logEntry
+	}{
+		"CompositeMerged": {
+			reason: "Spec and status merges should be logged with the XRD, CRD, and version.",
+			fn:     ForCompositeResource,
+			xrd:    minimalXRD(`{"properties":{"spec":{"properties":{"a":{"type":"string"}},"type":"object"}},"type":"object"}`),
+			want: []logEntry{
+				{Message: "Merged spec properties", KeysAndValues: []interface{}{"xrd", "coolcomposites.example.org", "crd", "coolcomposites.example.org", "version", "v1alpha1", "user", 1, "total", len(CompositeResourceSpecProps()) + 1}},
+				{Message: "Merged status properties", KeysAndValues: []interface{}{"xrd", "coolcomposites.example.org", "crd", "coolcomposites.example.org", "version", "v1alpha1", "user", 0, "total", len(CompositeResourceStatusProps())}},
+			},
+		},
+		"ClaimStatusConflict": {
+			reason: "Ignoring user-defined status properties that conflict with injected ones should be logged.",
+			fn:     ForCompositeResourceClaim,
+			xrd:    minimalXRD(`{"properties":{"status":{"properties":{"conditions":{"type":"string"}},"type":"object"}},"type":"object"}`),
+			want: []logEntry{
+				{Message: "Merged spec properties", KeysAndValues: []interface{}{"xrd", "coolcomposites.example.org", "crd", "coolclaims.example.org", "version", "v1alpha1", "user", 0, "total", len(CompositeResourceClaimSpecProps())}},
+				{Message: "Ignoring user-defined status properties that conflict with injected status properties", KeysAndValues: []interface{}{"xrd", "coolcomposites.example.org", "crd", "coolclaims.example.org", "version", "v1alpha1", "ignored", []string{"conditions"}}},
+				{Message: "Merged status properties", KeysAndValues: []interface{}{"xrd", "coolcomposites.example.org", "crd", "coolclaims.example.org", "version", "v1alpha1", "user", 1, "total", len(CompositeResourceStatusProps())}},
+			},
+		},
+		"InvalidVersions": {
+			reason: "A failure to validate the XRD's versions should be logged.",
+			fn:     ForCompositeResource,
+			xrd:    invalid,
+			want: []logEntry{
+				{Message: errInvalidVersions, KeysAndValues: []interface{}{"xrd", "coolcomposites.example.org", "crd", "coolcomposites.example.org", "error", errors.New(errMissingVersions)}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []logEntry{}
+			_, _ = tc.fn(tc.xrd, WithLogger(fakeLogger{entries: &got}))
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nWithLogger(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithPreserveUnknownSpecFields(t *testing.T) {
+	preserve := true
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   *bool
+	}{
+		"Strict": {
+			reason: "Unknown spec fields should be pruned by default.",
+			want:   nil,
+		},
+		"Permissive": {
+			reason: "Unknown spec fields should be preserved when requested.",
+			opts:   []Option{WithPreserveUnknownSpecFields()},
+			want:   &preserve,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{"properties":{"spec":{"properties":{"storageGB":{"type":"integer"}},"type":"object"}},"type":"object"}`)
+
+			xr, err := ForCompositeResource(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xr.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].XPreserveUnknownFields); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			xrc, err := ForCompositeResourceClaim(d, tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xrc.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].XPreserveUnknownFields); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPrinterColumnsConflict(t *testing.T) {
+	d := minimalXRD(`{}`)
+	d.Spec.Versions[0].AdditionalPrinterColumns = []extv1.CustomResourceColumnDefinition{
+		{Name: "CLASS", Type: "string", JSONPath: ".spec.class"},
+		{Name: "READY", Type: "string", JSONPath: ".status.ready"},
+	}
+
+	want := errors.Wrapf(errors.Errorf(errFmtDuplicatePrinterColumn, "READY"), errFmtInvalidPrinterColumns, "v1alpha1")
+
+	_, err := ForCompositeResource(d)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ForCompositeResource(...): -want error, +got error:\n%s", diff)
+	}
+
+	_, err = ForCompositeResourceClaim(d)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ForCompositeResourceClaim(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestPrinterColumnsInjectedPriority(t *testing.T) {
+	user := []extv1.CustomResourceColumnDefinition{{Name: "CLASS", Type: "string", JSONPath: ".spec.class", Priority: 1}}
+	injected := []extv1.CustomResourceColumnDefinition{{Name: "READY", Type: "string", JSONPath: ".status.ready", Priority: 1}}
+
+	want := []extv1.CustomResourceColumnDefinition{
+		{Name: "CLASS", Type: "string", JSONPath: ".spec.class", Priority: 1},
+		{Name: "READY", Type: "string", JSONPath: ".status.ready", Priority: 0},
+	}
+
+	got, err := printerColumns(user, injected)
+	if err != nil {
+		t.Fatalf("printerColumns(...): %s", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("printerColumns(...): injected columns should have priority 0: -want, +got:\n%s", diff)
+	}
+	if injected[0].Priority != 1 {
+		t.Errorf("printerColumns(...): the supplied injected columns should not be modified")
+	}
+}
+
+func TestValidateCRDName(t *testing.T) {
+	long := strings.Repeat("a", 64)
+	longer := strings.Repeat(long[:60]+".", 5) + "example.org"
+
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   error
+	}{
+		"Valid": {
+			reason: "A short, lowercase DNS subdomain is a valid CRD name.",
+			name:   "coolclaims.example.org",
+		},
+		"TooLong": {
+			reason: "CRD names must be no longer than 253 characters.",
+			name:   longer,
+			want:   errors.Errorf(errFmtInvalidName, longer, strings.Join(validation.IsDNS1123Subdomain(longer), ", ")),
+		},
+		"LabelTooLong": {
+			reason: "Each DNS label of a CRD name must be no longer than 63 characters.",
+			name:   long + ".example.org",
+			want:   errors.Errorf(errFmtInvalidNameLabel, long, strings.Join(validation.IsDNS1123Label(long), ", ")),
+		},
+		"NotLowercase": {
+			reason: "CRD names must be lowercase.",
+			name:   "CoolClaims.example.org",
+			want:   errors.Errorf(errFmtInvalidName, "CoolClaims.example.org", strings.Join(validation.IsDNS1123Subdomain("CoolClaims.example.org"), ", ")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateCRDName(tc.name)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateCRDName(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestForCompositeResourceClaimNameTooLong(t *testing.T) {
+	d := minimalXRD(`{}`)
+	d.Spec.Group = strings.Repeat("cool.", 50) + "example.org"
+
+	name := "coolclaims." + d.Spec.Group
+	want := errors.Wrap(errors.Errorf(errFmtInvalidName, name, strings.Join(validation.IsDNS1123Subdomain(name), ", ")), errInvalidCRDName)
+
+	_, err := ForCompositeResourceClaim(d)
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("ForCompositeResourceClaim(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestUserSpecPropsPreserved(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		schema string
+		prop   string
+		want   extv1.JSONSchemaProps
+	}{
+		"Nullable": {
+			reason: "Properties that allow explicit nulls should continue to do so.",
+			schema: `{"properties":{"spec":{"properties":{"engineVersion":{"type":"string","nullable":true}},"type":"object"}},"type":"object"}`,
+			prop:   "engineVersion",
+			want:   extv1.JSONSchemaProps{Type: "string", Nullable: true},
+		},
+		"Format": {
+			reason: "Formats, including those api-server does not recognise, should be preserved.",
+			schema: `{"properties":{"spec":{"properties":{"contact":{"type":"object","properties":{"email":{"type":"string","format":"email"},"region":{"type":"string","format":"cloud-region"}}}},"type":"object"}},"type":"object"}`,
+			prop:   "contact",
+			want: extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"email":  {Type: "string", Format: "email"},
+					"region": {Type: "string", Format: "cloud-region"},
+				},
+			},
+		},
+		"ArrayConstraints": {
+			reason: "Length and uniqueness constraints on array properties should be preserved.",
+			schema: `{"properties":{"spec":{"properties":{"zones":{"type":"array","minItems":1,"maxItems":3,"uniqueItems":true,"items":{"type":"string"}}},"type":"object"}},"type":"object"}`,
+			prop:   "zones",
+			want: extv1.JSONSchemaProps{
+				Type:        "array",
+				MinItems:    pointer.Int64Ptr(1),
+				MaxItems:    pointer.Int64Ptr(3),
+				UniqueItems: true,
+				Items:       &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}},
+			},
+		},
+		"NestedDefaults": {
+			reason: "Defaults on nested properties should be preserved.",
+			schema: `{"properties":{"spec":{"properties":{"parameters":{"type":"object","default":{},"properties":{"storage":{"type":"object","default":{},"properties":{"sizeGB":{"type":"integer","default":20},"class":{"type":"string","default":"standard"}}}}}},"type":"object"}},"type":"object"}`,
+			prop:   "parameters",
+			want: extv1.JSONSchemaProps{
+				Type:    "object",
+				Default: &extv1.JSON{Raw: []byte(`{}`)},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"storage": {
+						Type:    "object",
+						Default: &extv1.JSON{Raw: []byte(`{}`)},
+						Properties: map[string]extv1.JSONSchemaProps{
+							"sizeGB": {Type: "integer", Default: &extv1.JSON{Raw: []byte(`20`)}},
+							"class":  {Type: "string", Default: &extv1.JSON{Raw: []byte(`"standard"`)}},
+						},
+					},
+				},
+			},
+		},
+		"Example": {
+			reason: "Examples on properties should be preserved.",
+			schema: `{"properties":{"spec":{"properties":{"engineVersion":{"type":"string","example":"5.7"}},"type":"object"}},"type":"object"}`,
+			prop:   "engineVersion",
+			want:   extv1.JSONSchemaProps{Type: "string", Example: &extv1.JSON{Raw: []byte(`"5.7"`)}},
+		},
+		"NestedAdditionalProperties": {
+			reason: "The value schemas of nested maps of objects should be preserved.",
+			schema: `{"properties":{"spec":{"properties":{"pools":{"type":"object","additionalProperties":{"type":"object","properties":{"size":{"type":"integer"},"labels":{"type":"object","additionalProperties":{"type":"string"}}}}}},"type":"object"}},"type":"object"}`,
+			prop:   "pools",
+			want: extv1.JSONSchemaProps{
+				Type: "object",
+				AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &extv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]extv1.JSONSchemaProps{
+							"size": {Type: "integer"},
+							"labels": {
+								Type: "object",
+								AdditionalProperties: &extv1.JSONSchemaPropsOrBool{
+									Allows: true,
+									Schema: &extv1.JSONSchemaProps{Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			xr, err := ForCompositeResource(minimalXRD(tc.schema))
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			got := xr.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties[tc.prop]
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			claim, err := ForCompositeResourceClaim(minimalXRD(tc.schema))
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			got = claim.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties[tc.prop]
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSpecKeywordsPreserved(t *testing.T) {
+	schema := `{"properties":{"spec":{"type":"object","oneOf":[{"required":["small"]},{"required":["large"]}],"not":{"required":["medium"]},"example":{"small":{}},"properties":{"small":{"type":"object"},"large":{"type":"object"}}}},"type":"object"}`
+
+	want := extv1.JSONSchemaProps{
+		OneOf: []extv1.JSONSchemaProps{
+			{Required: []string{"small"}},
+			{Required: []string{"large"}},
+		},
+		Not:     &extv1.JSONSchemaProps{Required: []string{"medium"}},
+		Example: &extv1.JSON{Raw: []byte(`{"small":{}}`)},
+	}
+
+	xr, err := ForCompositeResource(minimalXRD(schema))
+	if err != nil {
+		t.Fatalf("ForCompositeResource(...): %s", err)
+	}
+	got := xr.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(extv1.JSONSchemaProps{}, "Type", "Properties")); diff != "" {
+		t.Errorf("ForCompositeResource(...): -want, +got:\n%s", diff)
+	}
+
+	claim, err := ForCompositeResourceClaim(minimalXRD(schema))
+	if err != nil {
+		t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+	}
+	got = claim.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(extv1.JSONSchemaProps{}, "Type", "Properties")); diff != "" {
+		t.Errorf("ForCompositeResourceClaim(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestForXRD(t *testing.T) {
+	withoutClaim := minimalXRD(`{}`)
+	withoutClaim.Spec.ClaimNames = nil
+
+	invalidClaim := minimalXRD(`{}`)
+	invalidClaim.Spec.ClaimNames.Kind = invalidClaim.Spec.Names.Kind
+
+	type want struct {
+		composite bool
+		claim     bool
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   want
+	}{
+		"WithClaim": {
+			reason: "Both CRDs should be returned when the XRD offers a claim.",
+			xrd:    minimalXRD(`{}`),
+			want:   want{composite: true, claim: true},
+		},
+		"WithoutClaim": {
+			reason: "Only the composite CRD should be returned when the XRD does not offer a claim.",
+			xrd:    withoutClaim,
+			want:   want{composite: true},
+		},
+		"InvalidComposite": {
+			reason: "An error should be returned if the composite CRD cannot be generated.",
+			xrd:    &v1alpha1.CompositeResourceDefinition{},
+			want: want{
+				err: errors.Wrap(errors.Wrap(errors.New(errMissingVersions), errInvalidVersions), errGenerateComposite),
+			},
+		},
+		"InvalidClaim": {
+			reason: "An error should be returned if the claim CRD cannot be generated.",
+			xrd:    invalidClaim,
+			want: want{
+				err: errors.Wrap(errors.Wrap(conflictingClaimName("kind", "CoolComposite"), errInvalidClaimNames), errGenerateClaim),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			composite, claim, err := ForXRD(tc.xrd)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nForXRD(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.composite, composite != nil); diff != "" {
+				t.Errorf("\n%s\nForXRD(...): -want composite, +got composite:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.claim, claim != nil); diff != "" {
+				t.Errorf("\n%s\nForXRD(...): -want claim, +got claim:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		base    map[string]string
+		overlay map[string]string
+		want    map[string]string
+	}{
+		"BothEmpty": {
+			reason: "Merging two empty maps should return an empty, non-nil map.",
+			want:   map[string]string{},
+		},
+		"EmptyOverlay": {
+			reason: "The base labels should be returned when there are no overlay labels.",
+			base:   map[string]string{LabelKeyManagedBy: LabelValueManagedBy},
+			want:   map[string]string{LabelKeyManagedBy: LabelValueManagedBy},
+		},
+		"EmptyBase": {
+			reason:  "The overlay labels should be returned when there are no base labels.",
+			overlay: map[string]string{"cool": "very"},
+			want:    map[string]string{"cool": "very"},
+		},
+		"Overlap": {
+			reason:  "Labels from both maps should be returned.",
+			base:    map[string]string{LabelKeyManagedBy: LabelValueManagedBy},
+			overlay: map[string]string{"cool": "very"},
+			want:    map[string]string{LabelKeyManagedBy: LabelValueManagedBy, "cool": "very"},
+		},
+		"ReservedKey": {
+			reason:  "Base labels should take precedence over overlay labels with the same key.",
+			base:    map[string]string{LabelKeyManagedBy: LabelValueManagedBy},
+			overlay: map[string]string{LabelKeyManagedBy: "helm", "cool": "very"},
+			want:    map[string]string{LabelKeyManagedBy: LabelValueManagedBy, "cool": "very"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := mergeLabels(tc.base, tc.overlay)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nmergeLabels(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("ReservedKeyInGeneratedCRDs", func(t *testing.T) {
+		d := minimalXRD(`{}`)
+		d.SetLabels(map[string]string{LabelKeyManagedBy: "helm", LabelKeyOwnedByXRD: "other"})
+
+		want := map[string]string{LabelKeyManagedBy: LabelValueManagedBy, LabelKeyOwnedByXRD: d.GetName()}
+
+		xr, err := ForCompositeResource(d)
+		if err != nil {
+			t.Fatalf("ForCompositeResource(...): %s", err)
+		}
+		if diff := cmp.Diff(want, xr.GetLabels()); diff != "" {
+			t.Errorf("\nForCompositeResource(...): -want, +got:\n%s", diff)
+		}
+
+		xrc, err := ForCompositeResourceClaim(d)
+		if err != nil {
+			t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+		}
+		if diff := cmp.Diff(want, xrc.GetLabels()); diff != "" {
+			t.Errorf("\nForCompositeResourceClaim(...): -want, +got:\n%s", diff)
+		}
+	})
+}
+
+func TestOwnedByXRD(t *testing.T) {
+	long := minimalXRD(`{}`)
+	long.Spec.Group = "database.platform.internal.example.org"
+	long.Spec.Names.Plural = "compositepostgresqlinstances"
+	long.SetName(long.Spec.Names.Plural + "." + long.Spec.Group)
+
+	type want struct {
+		label      string
+		annotation string
+	}
+
+	cases := map[string]struct {
+		reason string
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   want
+	}{
+		"ShortName": {
+			reason: "The owned-by label of CRDs generated from an XRD with a short name should be the XRD's name.",
+			xrd:    minimalXRD(`{}`),
+			want: want{
+				label: "coolcomposites.example.org",
+			},
+		},
+		"LongName": {
+			reason: "The owned-by label of CRDs generated from an XRD with a name longer than 63 characters should be shortened, and the full name should be annotated.",
+			xrd:    long,
+			want: want{
+				label:      OwnedByXRDLabelValue(long.GetName()),
+				annotation: long.GetName(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			xr, err := ForCompositeResource(tc.xrd)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			xrc, err := ForCompositeResourceClaim(tc.xrd)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+
+			for _, crd := range []*extv1.CustomResourceDefinition{xr, xrc} {
+				l := crd.GetLabels()[LabelKeyOwnedByXRD]
+				if diff := cmp.Diff(tc.want.label, l); diff != "" {
+					t.Errorf("\n%s\nFor(...) %s: -want label, +got label:\n%s", tc.reason, crd.GetName(), diff)
+				}
+				if errs := validation.IsValidLabelValue(l); len(errs) > 0 {
+					t.Errorf("\n%s\nFor(...) %s: invalid label value %q: %s", tc.reason, crd.GetName(), l, strings.Join(errs, ", "))
+				}
+				if diff := cmp.Diff(tc.want.annotation, crd.GetAnnotations()[AnnotationKeyOwnedByXRD]); diff != "" {
+					t.Errorf("\n%s\nFor(...) %s: -want annotation, +got annotation:\n%s", tc.reason, crd.GetName(), diff)
+				}
+			}
+		})
+	}
+}
+
+func TestOwnedByXRDLabelValue(t *testing.T) {
+	a := strings.Repeat("a", 70) + ".example.org"
+	b := strings.Repeat("a", 70) + ".example.com"
+
+	if got := OwnedByXRDLabelValue(a); len(got) != validation.LabelValueMaxLength {
+		t.Errorf("OwnedByXRDLabelValue(...): want %d characters, got %d", validation.LabelValueMaxLength, len(got))
+	}
+	if OwnedByXRDLabelValue(a) == OwnedByXRDLabelValue(b) {
+		t.Errorf("OwnedByXRDLabelValue(...): long names that share a prefix should not share a label value")
+	}
+}
+
+func TestVersionsAnnotation(t *testing.T) {
+	multi := minimalXRD(`{}`)
+	multi.SetAnnotations(map[string]string{"cool": "very"})
+	multi.Spec.Versions[0].Referenceable = false
+	multi.Spec.Versions = append(multi.Spec.Versions,
+		v1alpha1.CompositeResourceDefinitionVersion{Name: "v1", Served: true},
+		v1alpha1.CompositeResourceDefinitionVersion{Name: "v1beta1", Referenceable: true, Served: true},
+	)
+
+	type want struct {
+		annotations map[string]string
+		versions    []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   want
+	}{
+		"SingleVersion": {
+			reason: "CRDs with a single version should not be annotated with their versions.",
+			xrd:    minimalXRD(`{}`),
+			want:   want{},
+		},
+		"MultipleVersions": {
+			reason: "CRDs with multiple versions should be annotated with their versions in API discovery order.",
+			xrd:    multi,
+			want: want{
+				annotations: map[string]string{
+					"cool":                "very",
+					AnnotationKeyVersions: "v1,v1beta1,v1alpha1",
+				},
+				versions: []string{"v1", "v1beta1", "v1alpha1"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			composite, claim, err := ForXRD(tc.xrd)
+			if err != nil {
+				t.Fatalf("ForXRD(...): %s", err)
+			}
+			for _, crd := range []*extv1.CustomResourceDefinition{composite, claim} {
+				if diff := cmp.Diff(tc.want.annotations, crd.GetAnnotations()); diff != "" {
+					t.Errorf("\n%s\n%s: GetAnnotations(): -want, +got:\n%s", tc.reason, crd.GetName(), diff)
+				}
+				if diff := cmp.Diff(tc.want.versions, ParseVersions(crd)); diff != "" {
+					t.Errorf("\n%s\n%s: ParseVersions(...): -want, +got:\n%s", tc.reason, crd.GetName(), diff)
+				}
+			}
+			if _, ok := tc.xrd.GetAnnotations()[AnnotationKeyVersions]; ok {
+				t.Errorf("\n%s\nForXRD(...): the XRD's annotations should not be modified", tc.reason)
+			}
+		})
+	}
+}
+
+func TestWithStorageVersionAnnotation(t *testing.T) {
+	d := minimalXRD(`{}`)
+	d.SetAnnotations(map[string]string{"cool": "very"})
+
+	composite, claim, err := ForXRD(d, WithStorageVersionAnnotation())
+	if err != nil {
+		t.Fatalf("ForXRD(...): %s", err)
+	}
+
+	want := map[string]string{
+		"cool":                      "very",
+		AnnotationKeyStorageVersion: "v1alpha1",
+	}
+	for _, crd := range []*extv1.CustomResourceDefinition{composite, claim} {
+		if diff := cmp.Diff(want, crd.GetAnnotations()); diff != "" {
+			t.Errorf("%s: GetAnnotations(): -want, +got:\n%s", crd.GetName(), diff)
+		}
+	}
+	if _, ok := d.GetAnnotations()[AnnotationKeyStorageVersion]; ok {
+		t.Errorf("ForXRD(...): the XRD's annotations should not be modified")
+	}
+}
+
+func TestStorageVersionChanged(t *testing.T) {
+	annotated := func(v string) *extv1.CustomResourceDefinition {
+		crd := &extv1.CustomResourceDefinition{}
+		crd.SetAnnotations(map[string]string{AnnotationKeyStorageVersion: v})
+		return crd
+	}
+	stored := func(v string) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{Spec: extv1.CustomResourceDefinitionSpec{
+			Versions: []extv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Storage: v == "v1alpha1"},
+				{Name: "v1", Storage: v == "v1"},
+			},
+		}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		old    *extv1.CustomResourceDefinition
+		new    *extv1.CustomResourceDefinition
+		want   bool
+	}{
+		"Unchanged": {
+			reason: "The storage version has not changed if both CRDs are annotated with the same version.",
+			old:    annotated("v1alpha1"),
+			new:    annotated("v1alpha1"),
+			want:   false,
+		},
+		"Changed": {
+			reason: "The storage version has changed if the CRDs are annotated with different versions.",
+			old:    annotated("v1alpha1"),
+			new:    annotated("v1"),
+			want:   true,
+		},
+		"ChangedNotAnnotated": {
+			reason: "The storage version should be read from the versions of a CRD that is not annotated.",
+			old:    stored("v1alpha1"),
+			new:    annotated("v1"),
+			want:   true,
+		},
+		"UnchangedNotAnnotated": {
+			reason: "The storage version has not changed if neither CRD is annotated and both store the same version.",
+			old:    stored("v1"),
+			new:    stored("v1"),
+			want:   false,
+		},
+		"NoStorageVersion": {
+			reason: "The storage version has not changed if the old CRD has no storage version.",
+			old:    &extv1.CustomResourceDefinition{},
+			new:    annotated("v1"),
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := StorageVersionChanged(tc.old, tc.new)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nStorageVersionChanged(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestVersionDescription(t *testing.T) {
+	d := minimalXRD(`{"description":"A CoolComposite is very cool.","type":"object"}`)
+	d.Spec.Versions[0].Referenceable = false
+	d.Spec.Versions = append(d.Spec.Versions, v1alpha1.CompositeResourceDefinitionVersion{
+		Name:          "v1beta1",
+		Referenceable: true,
+		Served:        true,
+		Schema: &v1alpha1.CompositeResourceValidation{
+			OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(`{"description":"A CoolComposite is even cooler.","type":"object"}`)},
+		},
+	})
+
+	want := []string{"A CoolComposite is very cool.", "A CoolComposite is even cooler."}
+
+	composite, claim, err := ForXRD(d)
+	if err != nil {
+		t.Fatalf("ForXRD(...): %s", err)
+	}
+	for _, crd := range []*extv1.CustomResourceDefinition{composite, claim} {
+		got := make([]string, len(crd.Spec.Versions))
+		for i, v := range crd.Spec.Versions {
+			got[i] = v.Schema.OpenAPIV3Schema.Description
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("%s: ForXRD(...): -want descriptions, +got descriptions:\n%s", crd.GetName(), diff)
+		}
+	}
+}
+
+func TestRequiresRecreate(t *testing.T) {
+	crd := func(fns ...func(crd *extv1.CustomResourceDefinition)) *extv1.CustomResourceDefinition {
+		crd := &extv1.CustomResourceDefinition{
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group: "example.org",
+				Scope: extv1.ClusterScoped,
+				Versions: []extv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1", Served: true},
+					{Name: "v1beta1", Served: true, Storage: true},
+				},
+			},
+			Status: extv1.CustomResourceDefinitionStatus{
+				StoredVersions: []string{"v1alpha1", "v1beta1"},
+			},
+		}
+		for _, fn := range fns {
+			fn(crd)
+		}
+		return crd
+	}
+
+	cases := map[string]struct {
+		reason string
+		old    *extv1.CustomResourceDefinition
+		new    *extv1.CustomResourceDefinition
+		want   bool
+	}{
+		"NoChange": {
+			reason: "An unchanged CRD should not require recreation.",
+			old:    crd(),
+			new:    crd(),
+			want:   false,
+		},
+		"VersionAdded": {
+			reason: "Adding a version should not require recreation.",
+			old:    crd(),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Versions = append(crd.Spec.Versions, extv1.CustomResourceDefinitionVersion{Name: "v1", Served: true})
+			}),
+			want: false,
+		},
+		"VersionUnserved": {
+			reason: "Ceasing to serve a stored version should not require recreation.",
+			old:    crd(),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Versions[0].Served = false
+			}),
+			want: false,
+		},
+		"StorageVersionChanged": {
+			reason: "Changing which existing version is stored should not require recreation.",
+			old:    crd(),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Versions[0].Storage = true
+				crd.Spec.Versions[1].Storage = false
+			}),
+			want: false,
+		},
+		"ScopeChanged": {
+			reason: "Changing the scope of a CRD should require recreation.",
+			old:    crd(),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Scope = extv1.NamespaceScoped
+			}),
+			want: true,
+		},
+		"GroupChanged": {
+			reason: "Changing the group of a CRD should require recreation.",
+			old:    crd(),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Group = "example.net"
+			}),
+			want: true,
+		},
+		"StoredVersionRemoved": {
+			reason: "Removing a version that may have stored custom resources should require recreation.",
+			old:    crd(),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Versions = crd.Spec.Versions[1:]
+			}),
+			want: true,
+		},
+		"StorageVersionRemoved": {
+			reason: "Removing the storage version should require recreation.",
+			old: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Status.StoredVersions = nil
+			}),
+			new: crd(func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Versions = crd.Spec.Versions[:1]
+				crd.Spec.Versions[0].Storage = true
+			}),
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := RequiresRecreate(tc.old, tc.new)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRequiresRecreate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConversion(t *testing.T) {
+	url := "https://example.org/convert"
+
+	cases := map[string]struct {
+		reason     string
+		conversion *extv1.CustomResourceConversion
+		want       *extv1.CustomResourceConversion
+	}{
+		"NoConversion": {
+			reason: "Generated CRDs should not configure conversion if the XRD does not.",
+		},
+		"NoneStrategy": {
+			reason: "The None conversion strategy should be passed through unchanged.",
+			conversion: &extv1.CustomResourceConversion{
+				Strategy: extv1.NoneConverter,
+			},
+			want: &extv1.CustomResourceConversion{
+				Strategy: extv1.NoneConverter,
+			},
+		},
+		"ExplicitReviewVersions": {
+			reason: "Explicitly specified ConversionReview versions should be used.",
+			conversion: &extv1.CustomResourceConversion{
+				Strategy: extv1.WebhookConverter,
+				Webhook: &extv1.WebhookConversion{
+					ClientConfig:             &extv1.WebhookClientConfig{URL: &url},
+					ConversionReviewVersions: []string{"v1", "v1beta1"},
+				},
+			},
+			want: &extv1.CustomResourceConversion{
+				Strategy: extv1.WebhookConverter,
+				Webhook: &extv1.WebhookConversion{
+					ClientConfig:             &extv1.WebhookClientConfig{URL: &url},
+					ConversionReviewVersions: []string{"v1", "v1beta1"},
+				},
+			},
+		},
+		"DefaultReviewVersions": {
+			reason: "ConversionReview versions should default to v1 when the webhook strategy is used.",
+			conversion: &extv1.CustomResourceConversion{
+				Strategy: extv1.WebhookConverter,
+				Webhook: &extv1.WebhookConversion{
+					ClientConfig: &extv1.WebhookClientConfig{URL: &url},
+				},
+			},
+			want: &extv1.CustomResourceConversion{
+				Strategy: extv1.WebhookConverter,
+				Webhook: &extv1.WebhookConversion{
+					ClientConfig:             &extv1.WebhookClientConfig{URL: &url},
+					ConversionReviewVersions: []string{"v1"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+			d.Spec.Conversion = tc.conversion
+
+			xr, err := ForCompositeResource(d)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, xr.Spec.Conversion); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			claim, err := ForCompositeResourceClaim(d)
+			if err != nil {
+				t.Fatalf("ForCompositeResourceClaim(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, claim.Spec.Conversion); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithCompositeDeletePolicy(t *testing.T) {
+	withDefault := func(def string) *extv1.JSONSchemaProps {
+		p := CompositeDeletePolicyProps()["compositeDeletePolicy"]
+		p.Default = &extv1.JSON{Raw: []byte(`"` + def + `"`)}
+		return &p
+	}
+
+	type want struct {
+		prop *extv1.JSONSchemaProps
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		opts   []Option
+		want   want
+	}{
+		"Disabled": {
+			reason: "The compositeDeletePolicy field should not be injected by default.",
+		},
+		"Background": {
+			reason: "The compositeDeletePolicy field should be injected and default to Background.",
+			opts:   []Option{WithCompositeDeletePolicy(CompositeDeletePolicyBackground)},
+			want:   want{prop: withDefault(CompositeDeletePolicyBackground)},
+		},
+		"Foreground": {
+			reason: "The compositeDeletePolicy field should be injected and default to Foreground.",
+			opts:   []Option{WithCompositeDeletePolicy(CompositeDeletePolicyForeground)},
+			want:   want{prop: withDefault(CompositeDeletePolicyForeground)},
+		},
+		"InvalidPolicy": {
+			reason: "A default policy that is neither Background nor Foreground should be rejected.",
+			opts:   []Option{WithCompositeDeletePolicy("Orphan")},
+			want:   want{err: errors.Errorf(errFmtInvalidDeletePolicy, "Orphan")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResourceClaim(minimalXRD(`{}`), tc.opts...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			var got *extv1.JSONSchemaProps
+			if p, ok := crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["compositeDeletePolicy"]; ok {
+				got = &p
+			}
+			if diff := cmp.Diff(tc.want.prop, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResourceClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			xr, err := ForCompositeResource(minimalXRD(`{}`), tc.opts...)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			if _, ok := xr.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties["compositeDeletePolicy"]; ok {
+				t.Errorf("\n%s\nForCompositeResource(...): compositeDeletePolicy should not be injected into composite resources", tc.reason)
+			}
+		})
+	}
 }
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// XRDSpecEqual returns true if the supplied XRDs are equal in all the parts of
+// their spec that affect CRD generation, i.e. their group, names, claim names,
+// conversion settings, and versions, including their schemas. Schemas are
+// compared semantically, so two schemas that differ only in formatting are
+// considered equal.
+//
+// Only the spec is compared. Generated CRDs also copy the XRD's labels and
+// annotations, and reference its name and UID as their owner, so a change to
+// any of that metadata still requires the CRDs to be regenerated even when
+// XRDSpecEqual returns true. Callers that cache generated CRDs must also
+// compare that metadata, or regenerate whenever it changes.
+func XRDSpecEqual(a, b *v1alpha1.CompositeResourceDefinition) bool {
+	if a.Spec.Group != b.Spec.Group {
+		return false
+	}
+	if !cmp.Equal(a.Spec.Names, b.Spec.Names, cmpopts.EquateEmpty()) {
+		return false
+	}
+	if !cmp.Equal(a.Spec.ClaimNames, b.Spec.ClaimNames, cmpopts.EquateEmpty()) {
+		return false
+	}
+	if !cmp.Equal(a.Spec.Conversion, b.Spec.Conversion, cmpopts.EquateEmpty()) {
+		return false
+	}
+	return cmp.Equal(a.Spec.Versions, b.Spec.Versions, cmpopts.EquateEmpty(), cmp.Comparer(rawJSONEqual))
+}
+
+// rawJSONEqual returns true if the supplied raw extensions contain the same
+// JSON, ignoring formatting and the order of object keys.
+func rawJSONEqual(a, b runtime.RawExtension) bool {
+	if bytes.Equal(a.Raw, b.Raw) {
+		return true
+	}
+	var ja, jb interface{}
+	if err := json.Unmarshal(a.Raw, &ja); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b.Raw, &jb); err != nil {
+		return false
+	}
+	return cmp.Equal(ja, jb)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestXRDSpecEqual(t *testing.T) {
+	schema := `{"type":"object","properties":{"spec":{"type":"object","properties":{"cool":{"type":"string"}}}}}`
+
+	xrd := func(fn ...func(d *v1alpha1.CompositeResourceDefinition)) *v1alpha1.CompositeResourceDefinition {
+		d := minimalXRD(schema)
+		for _, f := range fn {
+			f(d)
+		}
+		return d
+	}
+
+	cases := map[string]struct {
+		reason string
+		a      *v1alpha1.CompositeResourceDefinition
+		b      *v1alpha1.CompositeResourceDefinition
+		want   bool
+	}{
+		"Equal": {
+			reason: "Identical XRDs should be equal.",
+			a:      xrd(),
+			b:      xrd(),
+			want:   true,
+		},
+		"IrrelevantFieldsDiffer": {
+			reason: "XRDs that differ only in fields that do not affect CRD generation should be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.SetResourceVersion("2")
+				d.Spec.ConnectionSecretKeys = []string{"password"}
+			}),
+			want: true,
+		},
+		"SchemaFormattingDiffers": {
+			reason: "XRDs whose schemas differ only in formatting should be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Versions[0].Schema.OpenAPIV3Schema = runtime.RawExtension{Raw: []byte(`{
+					"properties": {"spec": {"properties": {"cool": {"type": "string"}}, "type": "object"}},
+					"type": "object"
+				}`)}
+			}),
+			want: true,
+		},
+		"GroupDiffers": {
+			reason: "XRDs with different groups should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Group = "example.net"
+			}),
+			want: false,
+		},
+		"NamesDiffer": {
+			reason: "XRDs with different names should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Names.ShortNames = []string{"cc"}
+			}),
+			want: false,
+		},
+		"ClaimNamesDiffer": {
+			reason: "XRDs with different claim names should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.ClaimNames = nil
+			}),
+			want: false,
+		},
+		"ConversionDiffers": {
+			reason: "XRDs with different conversion settings should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Conversion = &extv1.CustomResourceConversion{Strategy: extv1.NoneConverter}
+			}),
+			want: false,
+		},
+		"VersionsDiffer": {
+			reason: "XRDs with different versions should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Versions[0].Served = false
+			}),
+			want: false,
+		},
+		"SchemasDiffer": {
+			reason: "XRDs with different schemas should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Versions[0].Schema.OpenAPIV3Schema = runtime.RawExtension{Raw: []byte(`{"type":"object"}`)}
+			}),
+			want: false,
+		},
+		"SchemaOverlaysDiffer": {
+			reason: "XRDs with different schema overlays should not be equal.",
+			a:      xrd(),
+			b: xrd(func(d *v1alpha1.CompositeResourceDefinition) {
+				d.Spec.Versions[0].SchemaOverlays = []v1alpha1.CompositeResourceValidation{{
+					OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(`{"type":"object"}`)},
+				}}
+			}),
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := XRDSpecEqual(tc.a, tc.b)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nXRDSpecEqual(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
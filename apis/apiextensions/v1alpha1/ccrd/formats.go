@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// knownFormats are the formats api-server validates string properties
+// against, and the numeric formats defined by OpenAPI. Formats are normalised
+// by removing hyphens, such that date-time is known as datetime.
+var knownFormats = sets.NewString(
+	"bsonobjectid", "uri", "email", "hostname", "ipv4", "ipv6", "cidr", "mac",
+	"uuid", "uuid3", "uuid4", "uuid5", "isbn", "isbn10", "isbn13", "creditcard",
+	"ssn", "hexcolor", "rgbcolor", "byte", "password", "date", "duration",
+	"datetime", "int32", "int64", "float", "double",
+)
+
+// FormatWarnings returns a warning for each property in the schema of every
+// version of the supplied CRD whose format is not recognised. Formats other
+// than those api-server validates, and the supplied extension formats (e.g.
+// Crossplane or platform specific formats that are validated elsewhere), are
+// not recognised. Unrecognised formats are preserved in the generated schema
+// but are not validated by api-server, so they are warnings rather than errors.
+// Warnings are sorted and prefixed with the path of the property, rooted at the
+// name of the supplied CRD.
+func FormatWarnings(crd *extv1.CustomResourceDefinition, extensions ...string) []string {
+	known := sets.NewString(extensions...).Union(knownFormats)
+	var warnings []string
+	p := field.NewPath("crds").Key(crd.GetName()).Child("spec", "versions")
+	for i, v := range crd.Spec.Versions {
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		warnings = append(warnings, formatWarnings(p.Index(i).Child("schema", "openAPIV3Schema"), v.Schema.OpenAPIV3Schema, known)...)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+func formatWarnings(p *field.Path, root *extv1.JSONSchemaProps, known sets.String) []string {
+	var warnings []string
+	_ = walkSchema(root, p, func(p *field.Path, s *extv1.JSONSchemaProps, _ int) error {
+		if s.Format != "" && !known.Has(s.Format) && !known.Has(strings.ReplaceAll(s.Format, "-", "")) {
+			warnings = append(warnings, fmt.Sprintf("%s: format %q is not recognised and will not be validated", p.Child("format"), s.Format))
+		}
+		return nil
+	})
+	return warnings
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatWarnings(t *testing.T) {
+	prefix := "crds[coolcomposites.example.org].spec.versions[0].schema.openAPIV3Schema.properties[spec].properties"
+
+	cases := map[string]struct {
+		reason     string
+		schema     string
+		extensions []string
+		want       []string
+	}{
+		"StandardFormats": {
+			reason: "Formats that api-server validates should not produce warnings.",
+			schema: `{"properties":{"spec":{"properties":{"email":{"type":"string","format":"email"},"created":{"type":"string","format":"date-time"},"size":{"type":"integer","format":"int64"}},"type":"object"}},"type":"object"}`,
+		},
+		"CustomFormat": {
+			reason: "Formats that are not recognised should produce a warning.",
+			schema: `{"properties":{"spec":{"properties":{"region":{"type":"string","format":"cloud-region"},"zones":{"type":"array","items":{"type":"string","format":"cloud-zone"}}},"type":"object"}},"type":"object"}`,
+			want: []string{
+				prefix + `[region].format: format "cloud-region" is not recognised and will not be validated`,
+				prefix + `[zones].items.format: format "cloud-zone" is not recognised and will not be validated`,
+			},
+		},
+		"ExtensionFormat": {
+			reason:     "Supplied extension formats should not produce warnings.",
+			schema:     `{"properties":{"spec":{"properties":{"region":{"type":"string","format":"cloud-region"},"zone":{"type":"string","format":"cloud-zone"}},"type":"object"}},"type":"object"}`,
+			extensions: []string{"cloud-region"},
+			want: []string{
+				prefix + `[zone].format: format "cloud-zone" is not recognised and will not be validated`,
+			},
+		},
+		"CombinatorFormat": {
+			reason: "Unrecognised formats of schemas nested within combinators should produce a warning.",
+			schema: `{"properties":{"spec":{"properties":{"endpoint":{"type":"string","oneOf":[{"format":"ipv4"},{"format":"cloud-endpoint"}]}},"type":"object"}},"type":"object"}`,
+			want: []string{
+				prefix + `[endpoint].oneOf[1].format: format "cloud-endpoint" is not recognised and will not be validated`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResource(minimalXRD(tc.schema))
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			got := FormatWarnings(crd, tc.extensions...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nFormatWarnings(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	errMarshalSpec      = "cannot marshal CustomResourceDefinition spec"
+	errCanonicalizeJSON = "cannot canonicalize JSON value"
+)
+
+// SpecHash returns a hex encoded SHA-256 hash of the spec of the supplied
+// CustomResourceDefinition. The hash depends only on the content of the spec;
+// object metadata such as the resource version, and the status of the CRD,
+// do not affect it. Two CRDs with semantically identical specs produce the
+// same hash regardless of the order in which their schema properties were set.
+// The raw JSON default, example, and enum values of their schemas are
+// canonicalized before hashing, so they may differ in formatting and object key
+// order, and their required properties may be listed in any order.
+func SpecHash(crd *extv1.CustomResourceDefinition) (string, error) {
+	spec := crd.Spec.DeepCopy()
+	for _, vr := range spec.Versions {
+		if vr.Schema == nil || vr.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		if err := canonicalize(vr.Schema.OpenAPIV3Schema); err != nil {
+			return "", err
+		}
+	}
+
+	// JSON encoding is canonical for our purposes; struct fields are always
+	// encoded in the same order and map keys are sorted.
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", errors.Wrap(err, errMarshalSpec)
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// canonicalize sorts the required properties of the supplied schema and its
+// subschemas, and canonicalizes their raw JSON values, in place.
+func canonicalize(s *extv1.JSONSchemaProps) error {
+	return walkSchema(s, nil, func(_ *field.Path, s *extv1.JSONSchemaProps, _ int) error {
+		sort.Strings(s.Required)
+		return canonicalizeValues(s)
+	})
+}
+
+// canonicalizeValues canonicalizes the raw JSON default, example, and enum
+// values of the supplied schema, in place.
+func canonicalizeValues(s *extv1.JSONSchemaProps) error {
+	values := []*extv1.JSON{s.Default, s.Example}
+	for i := range s.Enum {
+		values = append(values, &s.Enum[i])
+	}
+	for _, j := range values {
+		if err := canonicalizeJSON(j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canonicalizeJSON re-encodes the supplied raw JSON value, if any, with object
+// keys sorted and insignificant whitespace removed. Numbers are re-encoded as
+// written, so that integers too large for a float64 are not rounded.
+func canonicalizeJSON(j *extv1.JSON) error {
+	if j == nil || len(j.Raw) == 0 {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(j.Raw))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return errors.Wrap(err, errCanonicalizeJSON)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, errCanonicalizeJSON)
+	}
+	j.Raw = raw
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestSpecHash(t *testing.T) {
+	schema := `{"properties":{"spec":{"properties":{"engineVersion":{"type":"string"},"storageGB":{"type":"integer"}},"type":"object"}},"type":"object"}`
+	reordered := `{"type":"object","properties":{"spec":{"type":"object","properties":{"storageGB":{"type":"integer"},"engineVersion":{"type":"string"}}}}}`
+	changed := `{"properties":{"spec":{"properties":{"engineVersion":{"type":"string"},"storageGB":{"type":"number"}},"type":"object"}},"type":"object"}`
+
+	generate := func(t *testing.T, schema string, fn func(crd *extv1.CustomResourceDefinition)) string {
+		t.Helper()
+		crd, err := ForCompositeResource(minimalXRD(schema))
+		if err != nil {
+			t.Fatalf("ForCompositeResource(...): %s", err)
+		}
+		fn(crd)
+		h, err := SpecHash(crd)
+		if err != nil {
+			t.Fatalf("SpecHash(...): %s", err)
+		}
+		return h
+	}
+	noop := func(_ *extv1.CustomResourceDefinition) {}
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		fn     func(crd *extv1.CustomResourceDefinition)
+		equal  bool
+	}{
+		"IdenticalSpec": {
+			reason: "Identical specs should produce identical hashes.",
+			schema: schema,
+			fn:     noop,
+			equal:  true,
+		},
+		"ReorderedSchema": {
+			reason: "Specs generated from schemas that differ only in key order should produce identical hashes.",
+			schema: reordered,
+			fn:     noop,
+			equal:  true,
+		},
+		"MetadataAndStatusChanged": {
+			reason: "Changes to metadata and status should not change the hash.",
+			schema: schema,
+			fn: func(crd *extv1.CustomResourceDefinition) {
+				crd.SetResourceVersion("42")
+				crd.Status.StoredVersions = []string{"v1alpha1"}
+			},
+			equal: true,
+		},
+		"SchemaChanged": {
+			reason: "Changes to the schema should change the hash.",
+			schema: changed,
+			fn:     noop,
+			equal:  false,
+		},
+		"SpecFieldChanged": {
+			reason: "Changes to other spec fields should change the hash.",
+			schema: schema,
+			fn: func(crd *extv1.CustomResourceDefinition) {
+				crd.Spec.Versions[0].Served = false
+			},
+			equal: false,
+		},
+	}
+
+	want := generate(t, schema, noop)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := generate(t, tc.schema, tc.fn)
+			if diff := cmp.Diff(tc.equal, got == want); diff != "" {
+				t.Errorf("\n%s\nSpecHash(...): -want equal, +got equal:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSpecHashCanonicalizesValues(t *testing.T) {
+	// withSpec returns a function that sets the spec schema of a CRD's first
+	// version to the supplied schema.
+	withSpec := func(s extv1.JSONSchemaProps) func(crd *extv1.CustomResourceDefinition) {
+		return func(crd *extv1.CustomResourceDefinition) {
+			crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = s
+		}
+	}
+	withProp := func(p extv1.JSONSchemaProps) func(crd *extv1.CustomResourceDefinition) {
+		return withSpec(extv1.JSONSchemaProps{Type: "object", Properties: map[string]extv1.JSONSchemaProps{"cool": p}})
+	}
+
+	cases := map[string]struct {
+		reason string
+		a      func(crd *extv1.CustomResourceDefinition)
+		b      func(crd *extv1.CustomResourceDefinition)
+		equal  bool
+	}{
+		"RequiredOrder": {
+			reason: "Specs whose required properties differ only in order should produce identical hashes.",
+			a:      withSpec(extv1.JSONSchemaProps{Type: "object", Required: []string{"a", "b"}}),
+			b:      withSpec(extv1.JSONSchemaProps{Type: "object", Required: []string{"b", "a"}}),
+			equal:  true,
+		},
+		"DefaultFormatting": {
+			reason: "Specs whose defaults differ only in formatting and key order should produce identical hashes.",
+			a:      withProp(extv1.JSONSchemaProps{Type: "object", Default: &extv1.JSON{Raw: []byte(`{"a":1,"b":"x"}`)}}),
+			b:      withProp(extv1.JSONSchemaProps{Type: "object", Default: &extv1.JSON{Raw: []byte(`{ "b": "x", "a": 1 }`)}}),
+			equal:  true,
+		},
+		"ExampleFormatting": {
+			reason: "Specs whose examples differ only in formatting and key order should produce identical hashes.",
+			a:      withProp(extv1.JSONSchemaProps{Type: "object", Example: &extv1.JSON{Raw: []byte(`{"a":[1,2],"b":null}`)}}),
+			b:      withProp(extv1.JSONSchemaProps{Type: "object", Example: &extv1.JSON{Raw: []byte("{\n  \"b\": null,\n  \"a\": [1, 2]\n}")}}),
+			equal:  true,
+		},
+		"EnumFormatting": {
+			reason: "Specs whose enum values differ only in formatting should produce identical hashes.",
+			a:      withProp(extv1.JSONSchemaProps{Type: "object", Enum: []extv1.JSON{{Raw: []byte(`{"a":1,"b":2}`)}}}),
+			b:      withProp(extv1.JSONSchemaProps{Type: "object", Enum: []extv1.JSON{{Raw: []byte(`{"b": 2, "a": 1}`)}}}),
+			equal:  true,
+		},
+		"DefaultChanged": {
+			reason: "Specs with different defaults should produce different hashes.",
+			a:      withProp(extv1.JSONSchemaProps{Type: "integer", Default: &extv1.JSON{Raw: []byte(`1`)}}),
+			b:      withProp(extv1.JSONSchemaProps{Type: "integer", Default: &extv1.JSON{Raw: []byte(`2`)}}),
+			equal:  false,
+		},
+		"LargeIntegerDefaultChanged": {
+			reason: "Specs with different integer defaults too large for a float64 should produce different hashes.",
+			a:      withProp(extv1.JSONSchemaProps{Type: "integer", Default: &extv1.JSON{Raw: []byte(`9007199254740992`)}}),
+			b:      withProp(extv1.JSONSchemaProps{Type: "integer", Default: &extv1.JSON{Raw: []byte(`9007199254740993`)}}),
+			equal:  false,
+		},
+	}
+
+	hash := func(t *testing.T, fn func(crd *extv1.CustomResourceDefinition)) (string, *extv1.CustomResourceDefinition) {
+		t.Helper()
+		crd, err := ForCompositeResource(minimalXRD(`{}`))
+		if err != nil {
+			t.Fatalf("ForCompositeResource(...): %s", err)
+		}
+		fn(crd)
+		h, err := SpecHash(crd)
+		if err != nil {
+			t.Fatalf("SpecHash(...): %s", err)
+		}
+		return h, crd
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, _ := hash(t, tc.a)
+			b, crd := hash(t, tc.b)
+			if diff := cmp.Diff(tc.equal, a == b); diff != "" {
+				t.Errorf("\n%s\nSpecHash(...): -want equal, +got equal:\n%s", tc.reason, diff)
+			}
+
+			// SpecHash must not modify the CRD it hashes.
+			want, err := ForCompositeResource(minimalXRD(`{}`))
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			tc.b(want)
+			if diff := cmp.Diff(want, crd); diff != "" {
+				t.Errorf("\n%s\nSpecHash(...): the supplied CRD should not be modified: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const errFmtParseManagedFields = "cannot parse fields managed by %q"
+
+// fieldSpec is the key of the spec field in a FieldsV1 managed fields set.
+const fieldSpec = "f:spec"
+
+// ModifiedBy returns the sorted names of the field managers, other than the
+// supplied field manager, that manage any field of the spec of the supplied
+// CRD. The supplied field manager should be the one Crossplane uses when it
+// applies generated CRDs; any other field manager of the spec indicates that
+// the CRD was modified by a user or another controller.
+func ModifiedBy(crd *extv1.CustomResourceDefinition, fieldManager string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, mf := range crd.GetManagedFields() {
+		if mf.Manager == fieldManager || seen[mf.Manager] || mf.FieldsV1 == nil {
+			continue
+		}
+
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(mf.FieldsV1.Raw, &fields); err != nil {
+			return nil, errors.Wrapf(err, errFmtParseManagedFields, mf.Manager)
+		}
+		if _, ok := fields[fieldSpec]; ok {
+			seen[mf.Manager] = true
+		}
+	}
+
+	managers := make([]string, 0, len(seen))
+	for m := range seen {
+		managers = append(managers, m)
+	}
+	sort.Strings(managers)
+	return managers, nil
+}
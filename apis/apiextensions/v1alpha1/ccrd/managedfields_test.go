@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestManagedByLabel(t *testing.T) {
+	composite, claim, err := ForXRD(minimalXRD(`{}`))
+	if err != nil {
+		t.Fatalf("ForXRD(...): %s", err)
+	}
+	for _, crd := range []*extv1.CustomResourceDefinition{composite, claim} {
+		if diff := cmp.Diff(LabelValueManagedBy, crd.GetLabels()[LabelKeyManagedBy]); diff != "" {
+			t.Errorf("%s: ForXRD(...): -want managed-by label, +got managed-by label:\n%s", crd.GetName(), diff)
+		}
+	}
+}
+
+func TestModifiedBy(t *testing.T) {
+	entry := func(manager, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:    manager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	type want struct {
+		managers []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		mf     []metav1.ManagedFieldsEntry
+		want   want
+	}{
+		"Unmodified": {
+			reason: "A CRD whose spec is managed only by Crossplane should not be considered modified.",
+			mf: []metav1.ManagedFieldsEntry{
+				entry("crossplane", `{"f:spec":{"f:group":{}}}`),
+				entry("kube-apiserver", `{"f:status":{"f:conditions":{}}}`),
+			},
+			want: want{managers: []string{}},
+		},
+		"MetadataOnly": {
+			reason: "Changes to the metadata of a CRD should not be considered modifications of its spec.",
+			mf: []metav1.ManagedFieldsEntry{
+				entry("crossplane", `{"f:spec":{"f:group":{}}}`),
+				entry("kubectl", `{"f:metadata":{"f:labels":{"f:cool":{}}}}`),
+			},
+			want: want{managers: []string{}},
+		},
+		"Modified": {
+			reason: "Each field manager other than Crossplane that manages the spec should be returned once.",
+			mf: []metav1.ManagedFieldsEntry{
+				entry("crossplane", `{"f:spec":{"f:group":{}}}`),
+				entry("kubectl", `{"f:spec":{"f:versions":{}}}`),
+				entry("kubectl", `{"f:spec":{"f:names":{}}}`),
+				entry("argocd", `{"f:spec":{"f:scope":{}}}`),
+			},
+			want: want{managers: []string{"argocd", "kubectl"}},
+		},
+		"Malformed": {
+			reason: "An error should be returned if a set of managed fields cannot be parsed.",
+			mf: []metav1.ManagedFieldsEntry{
+				entry("kubectl", `{`),
+			},
+			want: want{err: errors.Wrapf(errTruncatedJSON(t), errFmtParseManagedFields, "kubectl")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd := &extv1.CustomResourceDefinition{}
+			crd.SetManagedFields(tc.mf)
+
+			got, err := ModifiedBy(crd, "crossplane")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nModifiedBy(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.managers, got); diff != "" {
+				t.Errorf("\n%s\nModifiedBy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// errTruncatedJSON returns the error encoding/json returns when parsing a truncated
+// JSON object.
+func errTruncatedJSON(t *testing.T) error {
+	t.Helper()
+	return json.Unmarshal([]byte(`{`), &map[string]json.RawMessage{})
+}
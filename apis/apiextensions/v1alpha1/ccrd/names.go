@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const errMixedCase = "may have mixed case, but should otherwise match: "
+
+// ValidateNames validates that the supplied names will be accepted by
+// api-server as the names of a CustomResourceDefinition. It applies the same
+// rules as api-server: plural, singular, short names, and categories must be
+// lowercase DNS-1035 labels, while kind and list kind must be DNS-1035 labels
+// once lowercased. Any problems are returned as an aggregate of field errors
+// rooted at names.
+func ValidateNames(n extv1.CustomResourceDefinitionNames) error {
+	p := field.NewPath("names")
+	errs := field.ErrorList{}
+
+	errs = append(errs, validateLowercaseName(p.Child("plural"), n.Plural, true)...)
+	errs = append(errs, validateLowercaseName(p.Child("singular"), n.Singular, false)...)
+	errs = append(errs, validateKindName(p.Child("kind"), n.Kind, true)...)
+	errs = append(errs, validateKindName(p.Child("listKind"), n.ListKind, false)...)
+	if n.Kind != "" && n.Kind == n.ListKind {
+		errs = append(errs, field.Invalid(p.Child("listKind"), n.ListKind, "kind and listKind may not be the same"))
+	}
+	for i, s := range n.ShortNames {
+		errs = append(errs, validateLowercaseName(p.Child("shortNames").Index(i), s, true)...)
+	}
+	for i, c := range n.Categories {
+		errs = append(errs, validateLowercaseName(p.Child("categories").Index(i), c, true)...)
+	}
+
+	return errs.ToAggregate()
+}
+
+func validateLowercaseName(p *field.Path, name string, required bool) field.ErrorList {
+	if name == "" {
+		if required {
+			return field.ErrorList{field.Required(p, "")}
+		}
+		return nil
+	}
+	if e := validation.IsDNS1035Label(name); len(e) > 0 {
+		return field.ErrorList{field.Invalid(p, name, strings.Join(e, ", "))}
+	}
+	return nil
+}
+
+func validateKindName(p *field.Path, name string, required bool) field.ErrorList {
+	if name == "" {
+		if required {
+			return field.ErrorList{field.Required(p, "")}
+		}
+		return nil
+	}
+	if e := validation.IsDNS1035Label(strings.ToLower(name)); len(e) > 0 {
+		return field.ErrorList{field.Invalid(p, name, errMixedCase+strings.Join(e, ", "))}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateNames(t *testing.T) {
+	valid := func(fn func(n *extv1.CustomResourceDefinitionNames)) extv1.CustomResourceDefinitionNames {
+		n := extv1.CustomResourceDefinitionNames{
+			Plural:     "coolcomposites",
+			Singular:   "coolcomposite",
+			ShortNames: []string{"cc"},
+			Kind:       "CoolComposite",
+			ListKind:   "CoolCompositeList",
+			Categories: []string{"cool"},
+		}
+		fn(&n)
+		return n
+	}
+
+	type want struct {
+		fields []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		names  extv1.CustomResourceDefinitionNames
+		want   want
+	}{
+		"Valid": {
+			reason: "Valid names should not return an error.",
+			names:  valid(func(_ *extv1.CustomResourceDefinitionNames) {}),
+			want:   want{},
+		},
+		"ValidWithoutOptionalNames": {
+			reason: "Singular, short names, list kind, and categories are optional.",
+			names:  extv1.CustomResourceDefinitionNames{Plural: "coolcomposites", Kind: "CoolComposite"},
+			want:   want{},
+		},
+		"MissingPlural": {
+			reason: "Plural is required.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.Plural = "" }),
+			want:   want{fields: []string{"names.plural"}},
+		},
+		"InvalidPlural": {
+			reason: "Plural must be a lowercase DNS-1035 label.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.Plural = "CoolComposites" }),
+			want:   want{fields: []string{"names.plural"}},
+		},
+		"InvalidSingular": {
+			reason: "Singular must be a lowercase DNS-1035 label.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.Singular = "cool.composite" }),
+			want:   want{fields: []string{"names.singular"}},
+		},
+		"InvalidShortName": {
+			reason: "Each short name must be a lowercase DNS-1035 label.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.ShortNames = []string{"cc", "1cc"} }),
+			want:   want{fields: []string{"names.shortNames[1]"}},
+		},
+		"MissingKind": {
+			reason: "Kind is required.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.Kind = "" }),
+			want:   want{fields: []string{"names.kind"}},
+		},
+		"InvalidKind": {
+			reason: "Kind may have mixed case, but must otherwise be a DNS-1035 label.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.Kind = "Cool_Composite" }),
+			want:   want{fields: []string{"names.kind"}},
+		},
+		"InvalidListKind": {
+			reason: "List kind may have mixed case, but must otherwise be a DNS-1035 label.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.ListKind = "CoolComposite-List!" }),
+			want:   want{fields: []string{"names.listKind"}},
+		},
+		"ListKindSameAsKind": {
+			reason: "List kind may not be the same as kind.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.ListKind = n.Kind }),
+			want:   want{fields: []string{"names.listKind"}},
+		},
+		"InvalidCategory": {
+			reason: "Each category must be a lowercase DNS-1035 label.",
+			names:  valid(func(n *extv1.CustomResourceDefinitionNames) { n.Categories = []string{"Cool"} }),
+			want:   want{fields: []string{"names.categories[0]"}},
+		},
+		"MultipleInvalid": {
+			reason: "All invalid names should be returned together.",
+			names: valid(func(n *extv1.CustomResourceDefinitionNames) {
+				n.Plural = "Cool"
+				n.Kind = "Cool Composite"
+			}),
+			want: want{fields: []string{"names.plural", "names.kind"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateNames(tc.names)
+
+			var got []string
+			if agg, ok := err.(utilerrors.Aggregate); ok {
+				for _, e := range agg.Errors() {
+					fe, ok := e.(*field.Error)
+					if !ok {
+						t.Fatalf("\n%s\nValidateNames(...): want *field.Error, got %T", tc.reason, e)
+					}
+					got = append(got, fe.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("\n%s\nValidateNames(...): want aggregate error, got %T", tc.reason, err)
+			}
+
+			if diff := cmp.Diff(tc.want.fields, got); diff != "" {
+				t.Errorf("\n%s\nValidateNames(...): -want invalid fields, +got invalid fields:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	d := minimalXRD(`{"type":"object"}`)
+	d.Spec.Names.Plural = "CoolComposites"
+	if _, err := ForCompositeResource(d); err == nil {
+		t.Errorf("ForCompositeResource(...): expected error for invalid plural")
+	}
+
+	d = minimalXRD(`{"type":"object"}`)
+	d.Spec.ClaimNames.Kind = "Cool Claim"
+	if _, err := ForCompositeResourceClaim(d); err == nil {
+		t.Errorf("ForCompositeResourceClaim(...): expected error for invalid kind")
+	}
+}
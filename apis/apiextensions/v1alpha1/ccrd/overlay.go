@@ -0,0 +1,170 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+const (
+	errMarshalSchema       = "cannot marshal merged validation schema"
+	errFmtParseOverlay     = "cannot parse schema overlay %d"
+	errFmtMergeOverlay     = "cannot merge schema overlay %d"
+	errFmtConflictingTypes = "cannot change the type of %q from %q to %q"
+)
+
+// Schema keywords whose values are themselves schemas, and should thus be
+// merged rather than replaced.
+var schemaKeywords = map[string]bool{
+	"items":                true,
+	"additionalProperties": true,
+	"not":                  true,
+}
+
+// getSchema returns the OpenAPI v3 schema of the supplied version. This is the
+// result of merging each of the version's schema overlays, in order, over its
+// schema. A nil schema is returned if the version specifies neither.
+func getSchema(vr v1alpha1.CompositeResourceDefinitionVersion) (*extv1.JSONSchemaProps, error) {
+	if vr.Schema == nil && len(vr.SchemaOverlays) == 0 {
+		return nil, nil
+	}
+
+	merged := map[string]interface{}{}
+	if vr.Schema != nil {
+		if err := unmarshalSchema(vr.Schema.OpenAPIV3Schema.Raw, &merged); err != nil {
+			return nil, errors.Wrap(err, errParseValidation)
+		}
+	}
+
+	for i, o := range vr.SchemaOverlays {
+		overlay := map[string]interface{}{}
+		if err := unmarshalSchema(o.OpenAPIV3Schema.Raw, &overlay); err != nil {
+			return nil, errors.Wrapf(err, errFmtParseOverlay, i)
+		}
+		if err := mergeSchema(merged, overlay, ""); err != nil {
+			return nil, errors.Wrapf(err, errFmtMergeOverlay, i)
+		}
+	}
+
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, errMarshalSchema)
+	}
+
+	s := &extv1.JSONSchemaProps{}
+	return s, errors.Wrap(json.Unmarshal(raw, s), errParseValidation)
+}
+
+// unmarshalSchema unmarshals the supplied raw schema, preserving the precision
+// of any numbers (e.g. maximum or minimum values) it contains.
+func unmarshalSchema(raw []byte, into *map[string]interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	return d.Decode(into)
+}
+
+// mergeSchema deeply merges the src schema into the dst schema. Properties and
+// other schema-valued keywords are merged recursively, required properties are
+// combined, and all other keywords set by src replace those set by dst. The
+// supplied path identifies the schema being merged in errors.
+func mergeSchema(dst, src map[string]interface{}, path string) error {
+	for k, sv := range src {
+		dv, ok := dst[k]
+		if !ok {
+			dst[k] = sv
+			continue
+		}
+
+		switch {
+		case k == "type":
+			// Types may be arrays, e.g. ["string", "null"], which are not
+			// comparable using !=.
+			if !reflect.DeepEqual(dv, sv) {
+				return errors.Errorf(errFmtConflictingTypes, pathOrRoot(path), dv, sv)
+			}
+		case k == "required":
+			dst[k] = mergeRequired(dv, sv)
+		case k == "properties":
+			dp, dok := dv.(map[string]interface{})
+			sp, sok := sv.(map[string]interface{})
+			if !dok || !sok {
+				dst[k] = sv
+				continue
+			}
+			for name, prop := range sp {
+				d, dok := dp[name].(map[string]interface{})
+				s, sok := prop.(map[string]interface{})
+				if !dok || !sok {
+					dp[name] = prop
+					continue
+				}
+				if err := mergeSchema(d, s, path+"."+name); err != nil {
+					return err
+				}
+			}
+		case schemaKeywords[k]:
+			d, dok := dv.(map[string]interface{})
+			s, sok := sv.(map[string]interface{})
+			if !dok || !sok {
+				dst[k] = sv
+				continue
+			}
+			if err := mergeSchema(d, s, path); err != nil {
+				return err
+			}
+		default:
+			dst[k] = sv
+		}
+	}
+	return nil
+}
+
+// mergeRequired returns the union of two lists of required properties,
+// preserving the order in which they appear.
+func mergeRequired(dst, src interface{}) interface{} {
+	d, dok := dst.([]interface{})
+	s, sok := src.([]interface{})
+	if !dok || !sok {
+		return src
+	}
+
+	seen := make(map[interface{}]bool, len(d))
+	for _, r := range d {
+		seen[r] = true
+	}
+	for _, r := range s {
+		if !seen[r] {
+			d = append(d, r)
+			seen[r] = true
+		}
+	}
+	return d
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
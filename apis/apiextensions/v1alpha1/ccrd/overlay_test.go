@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestGetSchema(t *testing.T) {
+	validation := func(s string) v1alpha1.CompositeResourceValidation {
+		return v1alpha1.CompositeResourceValidation{OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(s)}}
+	}
+	base := validation(`{"type":"object","properties":{"spec":{"type":"object","required":["region"],"properties":{"region":{"type":"string"},"engine":{"type":"object","properties":{"name":{"type":"string"}}}}}}}`)
+
+	type want struct {
+		s   *extv1.JSONSchemaProps
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		vr     v1alpha1.CompositeResourceDefinitionVersion
+		want   want
+	}{
+		"NoSchema": {
+			reason: "A version with neither a schema nor overlays has no schema.",
+			vr:     v1alpha1.CompositeResourceDefinitionVersion{},
+			want:   want{},
+		},
+		"SchemaOnly": {
+			reason: "A version with no overlays should use its schema as is.",
+			vr: v1alpha1.CompositeResourceDefinitionVersion{
+				Schema: &v1alpha1.CompositeResourceValidation{OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(`{"type":"object","description":"cool"}`)}},
+			},
+			want: want{
+				s: &extv1.JSONSchemaProps{Type: "object", Description: "cool"},
+			},
+		},
+		"OverridePropertyAndMergeNestedObject": {
+			reason: "Later overlays should override earlier properties, while nested objects are merged.",
+			vr: v1alpha1.CompositeResourceDefinitionVersion{
+				Schema: &base,
+				SchemaOverlays: []v1alpha1.CompositeResourceValidation{
+					validation(`{"properties":{"spec":{"required":["engine"],"properties":{"region":{"type":"string","enum":["us-east-1"]},"engine":{"properties":{"version":{"type":"string"}}}}}}}`),
+					validation(`{"properties":{"spec":{"properties":{"region":{"description":"The region."}}}}}`),
+				},
+			},
+			want: want{
+				s: &extv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"spec": {
+							Type:     "object",
+							Required: []string{"region", "engine"},
+							Properties: map[string]extv1.JSONSchemaProps{
+								"region": {
+									Type:        "string",
+									Description: "The region.",
+									Enum:        []extv1.JSON{{Raw: []byte(`"us-east-1"`)}},
+								},
+								"engine": {
+									Type: "object",
+									Properties: map[string]extv1.JSONSchemaProps{
+										"name":    {Type: "string"},
+										"version": {Type: "string"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"OverlaysOnly": {
+			reason: "A version may be defined entirely by overlays.",
+			vr: v1alpha1.CompositeResourceDefinitionVersion{
+				SchemaOverlays: []v1alpha1.CompositeResourceValidation{
+					validation(`{"type":"object"}`),
+					validation(`{"description":"cool"}`),
+				},
+			},
+			want: want{
+				s: &extv1.JSONSchemaProps{Type: "object", Description: "cool"},
+			},
+		},
+		"ConflictingTypes": {
+			reason: "An overlay may not change the type of a property.",
+			vr: v1alpha1.CompositeResourceDefinitionVersion{
+				Schema: &base,
+				SchemaOverlays: []v1alpha1.CompositeResourceValidation{
+					validation(`{"properties":{"spec":{"properties":{"engine":{"type":"string"}}}}}`),
+				},
+			},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtConflictingTypes, ".spec.engine", "object", "string"), errFmtMergeOverlay, 0),
+			},
+		},
+		"UnparseableOverlay": {
+			reason: "We should return an error if an overlay cannot be parsed.",
+			vr: v1alpha1.CompositeResourceDefinitionVersion{
+				Schema:         &base,
+				SchemaOverlays: []v1alpha1.CompositeResourceValidation{validation(`{`)},
+			},
+			want: want{
+				err: errors.Wrapf(errors.New("unexpected EOF"), errFmtParseOverlay, 0),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := getSchema(tc.vr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ngetSchema(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.s, s); diff != "" {
+				t.Errorf("\n%s\ngetSchema(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergeSchema(t *testing.T) {
+	nullableString := func() []interface{} { return []interface{}{"string", "null"} }
+
+	type args struct {
+		dst map[string]interface{}
+		src map[string]interface{}
+	}
+	type want struct {
+		dst map[string]interface{}
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SameArrayTypes": {
+			reason: "Identical array-valued types should merge without error.",
+			args: args{
+				dst: map[string]interface{}{"type": nullableString()},
+				src: map[string]interface{}{"type": nullableString(), "description": "cool"},
+			},
+			want: want{
+				dst: map[string]interface{}{"type": nullableString(), "description": "cool"},
+			},
+		},
+		"DifferentArrayTypes": {
+			reason: "Differing array-valued types should conflict.",
+			args: args{
+				dst: map[string]interface{}{"type": nullableString()},
+				src: map[string]interface{}{"type": []interface{}{"integer", "null"}},
+			},
+			want: want{
+				dst: map[string]interface{}{"type": nullableString()},
+				err: errors.Errorf(errFmtConflictingTypes, ".", nullableString(), []interface{}{"integer", "null"}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := mergeSchema(tc.args.dst, tc.args.src, "")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nmergeSchema(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.dst, tc.args.dst); diff != "" {
+				t.Errorf("\n%s\nmergeSchema(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// maxResolvedSchemas is the maximum number of schemas a schema may contain once
+// its references have been inlined. Definitions that refer to one another more
+// than once grow exponentially when inlined, so without a limit a small schema
+// could expand to one that exhausts memory.
+const maxResolvedSchemas = 10000
+
+const (
+	refPrefix = "#/definitions/"
+
+	errFmtExternalRef    = "cannot resolve %q: only references to the schema's definitions are supported"
+	errFmtUnresolvedRef  = "cannot resolve %q: no such definition"
+	errFmtCircularRef    = "cannot resolve %q: circular reference"
+	errFmtTooManySchemas = "schema would contain more than %d schemas once its references were inlined"
+)
+
+// resolveRefs inlines each $ref in the supplied schema, which must refer to
+// one of the schema's top-level definitions, e.g. #/definitions/parameters.
+// Structural schemas may not contain $ref or definitions, so the definitions
+// are removed once every reference has been inlined. Keywords that appear
+// alongside a $ref are replaced by the referenced definition. An error is
+// returned if the resolved schema would contain more than maxResolvedSchemas
+// schemas.
+func resolveRefs(root *extv1.JSONSchemaProps) error {
+	r := &resolver{defs: root.Definitions}
+	root.Definitions = nil
+	return r.resolve(root, nil)
+}
+
+// A resolver inlines references to a set of definitions, counting the schemas
+// it produces.
+type resolver struct {
+	defs    extv1.JSONSchemaDefinitions
+	schemas int
+}
+
+func (r *resolver) resolve(s *extv1.JSONSchemaProps, seen []string) error {
+	if s.Ref != nil {
+		return r.inline(s, seen)
+	}
+
+	r.schemas++
+	if r.schemas > maxResolvedSchemas {
+		return errors.Errorf(errFmtTooManySchemas, maxResolvedSchemas)
+	}
+
+	return forEachSubschema(s, nil, func(_ *field.Path, c *extv1.JSONSchemaProps) error {
+		return r.resolve(c, seen)
+	})
+}
+
+// inline replaces the supplied schema, which must be a $ref, with the
+// definition it refers to, and then resolves any references in that
+// definition. The supplied names are those of the definitions that have
+// already been inlined on the path to the supplied schema.
+func (r *resolver) inline(s *extv1.JSONSchemaProps, seen []string) error {
+	ref := *s.Ref
+	if !strings.HasPrefix(ref, refPrefix) {
+		return errors.Errorf(errFmtExternalRef, ref)
+	}
+	name := strings.TrimPrefix(ref, refPrefix)
+	for _, n := range seen {
+		if n == name {
+			return errors.Errorf(errFmtCircularRef, ref)
+		}
+	}
+	d, ok := r.defs[name]
+	if !ok {
+		return errors.Errorf(errFmtUnresolvedRef, ref)
+	}
+	*s = *d.DeepCopy()
+	return r.resolve(s, append(append([]string{}, seen...), name))
+}
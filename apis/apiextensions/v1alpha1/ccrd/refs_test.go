@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// fanOut returns a schema with the supplied number of definitions, each of
+// which refers to the next twice. Inlining its references doubles the size of
+// the schema for each definition.
+func fanOut(n int) string {
+	defs := make([]string, 0, n+1)
+	for i := 0; i < n; i++ {
+		defs = append(defs, fmt.Sprintf(`"d%d":{"type":"object","properties":{"a":{"$ref":"#/definitions/d%d"},"b":{"$ref":"#/definitions/d%d"}}}`, i, i+1, i+1))
+	}
+	defs = append(defs, fmt.Sprintf(`"d%d":{"type":"string"}`, n))
+	return `{"definitions":{` + strings.Join(defs, ",") + `},"properties":{"spec":{"properties":{"tree":{"$ref":"#/definitions/d0"}},"type":"object"}},"type":"object"}`
+}
+
+func TestResolveRefs(t *testing.T) {
+	minimum := 1.0
+
+	type want struct {
+		prop extv1.JSONSchemaProps
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		field  string
+		want   want
+	}{
+		"InternalRef": {
+			reason: "References to definitions should be inlined.",
+			schema: `{"definitions":{"size":{"type":"integer","minimum":1}},"properties":{"spec":{"properties":{"storageGB":{"$ref":"#/definitions/size"}},"type":"object"}},"type":"object"}`,
+			field:  "storageGB",
+			want:   want{prop: extv1.JSONSchemaProps{Type: "integer", Minimum: &minimum}},
+		},
+		"NestedRef": {
+			reason: "References within definitions and array items should be inlined.",
+			schema: `{"definitions":{"zone":{"type":"string"},"zones":{"type":"array","items":{"$ref":"#/definitions/zone"}}},"properties":{"spec":{"properties":{"zones":{"$ref":"#/definitions/zones"}},"type":"object"}},"type":"object"}`,
+			field:  "zones",
+			want: want{prop: extv1.JSONSchemaProps{
+				Type:  "array",
+				Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}},
+			}},
+		},
+		"UnresolvedRef": {
+			reason: "An error should be returned if a reference does not refer to a definition.",
+			schema: `{"properties":{"spec":{"properties":{"storageGB":{"$ref":"#/definitions/size"}},"type":"object"}},"type":"object"}`,
+			want:   want{err: errors.Errorf(errFmtUnresolvedRef, "#/definitions/size")},
+		},
+		"ExternalRef": {
+			reason: "An error should be returned if a reference is not to one of the schema's definitions.",
+			schema: `{"properties":{"spec":{"properties":{"storageGB":{"$ref":"https://example.org/schema.json#/size"}},"type":"object"}},"type":"object"}`,
+			want:   want{err: errors.Errorf(errFmtExternalRef, "https://example.org/schema.json#/size")},
+		},
+		"CircularRef": {
+			reason: "An error should be returned if a definition refers to itself.",
+			schema: `{"definitions":{"node":{"type":"object","properties":{"child":{"$ref":"#/definitions/node"}}}},"properties":{"spec":{"properties":{"tree":{"$ref":"#/definitions/node"}},"type":"object"}},"type":"object"}`,
+			want:   want{err: errors.Errorf(errFmtCircularRef, "#/definitions/node")},
+		},
+		"SmallFanOut": {
+			reason: "Definitions that refer to one another more than once should be inlined if the result is small enough.",
+			schema: fanOut(2),
+			field:  "tree",
+			want: want{prop: extv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]extv1.JSONSchemaProps{
+					"a": {Type: "object", Properties: map[string]extv1.JSONSchemaProps{"a": {Type: "string"}, "b": {Type: "string"}}},
+					"b": {Type: "object", Properties: map[string]extv1.JSONSchemaProps{"a": {Type: "string"}, "b": {Type: "string"}}},
+				},
+			}},
+		},
+		"TooManySchemas": {
+			reason: "An error should be returned if inlining references would produce too many schemas.",
+			schema: fanOut(40),
+			want:   want{err: errors.Errorf(errFmtTooManySchemas, maxResolvedSchemas)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResource(minimalXRD(tc.schema))
+			if diff := cmp.Diff(tc.want.err, errors.Cause(err), test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nForCompositeResource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			root := crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+			if root.Definitions != nil {
+				t.Errorf("\n%s\nForCompositeResource(...): definitions should be removed", tc.reason)
+			}
+			got := root.Properties["spec"].Properties[tc.field]
+			if diff := cmp.Diff(tc.want.prop, got); diff != "" {
+				t.Errorf("\n%s\nForCompositeResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ import v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 // TODO(negz): Add descriptions to schema fields.
 
+var listTypeSet = "set"
+
 // BaseProps is a partial OpenAPIV3Schema for the spec fields that Crossplane
 // expects to be present for all CRDs that it creates.
 func BaseProps() map[string]v1.JSONSchemaProps {
@@ -107,6 +109,70 @@ func CompositeResourceSpecProps() map[string]v1.JSONSchemaProps {
 	}
 }
 
+// ManagementPoliciesProps is a partial OpenAPIV3Schema for the optional spec
+// fields that Crossplane may inject into defined infrastructure resources in
+// order to reserve a place for the operations it should perform on them.
+// Crossplane does not currently read the field.
+func ManagementPoliciesProps() map[string]v1.JSONSchemaProps {
+	return map[string]v1.JSONSchemaProps{
+		"managementPolicies": {
+			Description: "ManagementPolicies is reserved for the operations Crossplane should perform on this resource. Crossplane does not currently read it; every operation is performed regardless of its value.",
+			Type:        "array",
+			XListType:   &listTypeSet,
+			Items: &v1.JSONSchemaPropsOrArray{
+				Schema: &v1.JSONSchemaProps{
+					Type: "string",
+					Enum: []v1.JSON{
+						{Raw: []byte(`"Observe"`)},
+						{Raw: []byte(`"Create"`)},
+						{Raw: []byte(`"Update"`)},
+						{Raw: []byte(`"Delete"`)},
+						{Raw: []byte(`"LateInitialize"`)},
+						{Raw: []byte(`"*"`)},
+					},
+				},
+			},
+		},
+	}
+}
+
+// PausedProps is a partial OpenAPIV3Schema for the optional spec field that
+// Crossplane may inject into defined infrastructure resources in order to
+// reserve a place for tooling to record that their reconciliation should be
+// paused. Crossplane does not currently read the field.
+func PausedProps() map[string]v1.JSONSchemaProps {
+	return map[string]v1.JSONSchemaProps{
+		"paused": {
+			Description: "Paused is reserved for tooling that pauses reconciliation of this resource. Crossplane does not currently read it; setting it does not pause reconciliation.",
+			Type:        "boolean",
+		},
+	}
+}
+
+// EnvironmentConfigRefsProps is a partial OpenAPIV3Schema for the optional spec
+// field that Crossplane may inject into defined infrastructure resources in
+// order to reserve a place for references to EnvironmentConfigs. Crossplane
+// does not currently read the field.
+func EnvironmentConfigRefsProps() map[string]v1.JSONSchemaProps {
+	return map[string]v1.JSONSchemaProps{
+		"environmentConfigRefs": {
+			Description: "EnvironmentConfigRefs is reserved for references to EnvironmentConfigs. Crossplane does not currently read it; referenced EnvironmentConfigs are not made available to Compositions.",
+			Type:        "array",
+			Items: &v1.JSONSchemaPropsOrArray{
+				Schema: &v1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]v1.JSONSchemaProps{
+						"apiVersion": {Type: "string"},
+						"kind":       {Type: "string"},
+						"name":       {Type: "string"},
+					},
+					Required: []string{"apiVersion", "kind", "name"},
+				},
+			},
+		},
+	}
+}
+
 // CompositeResourceClaimSpecProps is a partial OpenAPIV3Schema for the spec
 // fields that Crossplane expects to be present for all published infrastructure
 // resources.
@@ -151,6 +217,22 @@ func CompositeResourceClaimSpecProps() map[string]v1.JSONSchemaProps {
 	}
 }
 
+// CompositeDeletePolicyProps is a partial OpenAPIV3Schema for the optional spec
+// field that Crossplane may inject into published infrastructure resources in
+// order to control how their composite resource is deleted.
+func CompositeDeletePolicyProps() map[string]v1.JSONSchemaProps {
+	return map[string]v1.JSONSchemaProps{
+		"compositeDeletePolicy": {
+			Description: "CompositeDeletePolicy controls whether the composite resource is deleted in the background or the foreground when this claim is deleted.",
+			Type:        "string",
+			Enum: []v1.JSON{
+				{Raw: []byte(`"Background"`)},
+				{Raw: []byte(`"Foreground"`)},
+			},
+		},
+	}
+}
+
 // CompositeResourceStatusProps is a partial OpenAPIV3Schema for the status
 // fields that Crossplane expects to be present for all defined or published
 // infrastructure resources.
@@ -173,6 +255,25 @@ func CompositeResourceStatusProps() map[string]v1.JSONSchemaProps {
 				},
 			},
 		},
+		"connectionDetails": {
+			Type: "object",
+			Properties: map[string]v1.JSONSchemaProps{
+				"lastPublishedTime": {Type: "string", Format: "date-time"},
+			},
+		},
+	}
+}
+
+// ObservedGenerationProps is a partial OpenAPIV3Schema for the optional status
+// field that Crossplane may inject into defined infrastructure resources in
+// order to advertise the generation it most recently observed.
+func ObservedGenerationProps() map[string]v1.JSONSchemaProps {
+	return map[string]v1.JSONSchemaProps{
+		"observedGeneration": {
+			Description: "ObservedGeneration is the most recent metadata.generation observed by Crossplane.",
+			Type:        "integer",
+			Format:      "int64",
+		},
 	}
 }
 
@@ -209,3 +310,15 @@ func CompositeResourceClaimPrinterColumns() []v1.CustomResourceColumnDefinition
 		},
 	}
 }
+
+// TerminatingPrinterColumns returns the set of optional printer columns that
+// indicate whether a composite resource is being deleted.
+func TerminatingPrinterColumns() []v1.CustomResourceColumnDefinition {
+	return []v1.CustomResourceColumnDefinition{
+		{
+			Name:     "TERMINATING",
+			Type:     "date",
+			JSONPath: ".metadata.deletionTimestamp",
+		},
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// DefaultMaxSize is the default maximum size, in bytes, of a serialized CRD.
+// It corresponds to etcd's default request size limit of 1.5MiB; larger CRDs
+// will be rejected when they are applied.
+const DefaultMaxSize = 1536 * 1024
+
+const (
+	errSerializeCRD = "cannot serialize CRD"
+	errFmtTooLarge  = "CRD is %d bytes when serialized, exceeding the maximum of %d"
+)
+
+// EstimateSize returns the size, in bytes, of the supplied CRD when serialized
+// as JSON. This approximates the size of the object api-server will store.
+func EstimateSize(crd *extv1.CustomResourceDefinition) (int, error) {
+	j, err := json.Marshal(crd)
+	if err != nil {
+		return 0, errors.Wrap(err, errSerializeCRD)
+	}
+	return len(j), nil
+}
+
+// CheckSize returns an error if the supplied CRD is larger than the supplied
+// number of bytes when serialized. Pass DefaultMaxSize to check the CRD against
+// etcd's default limit.
+func CheckSize(crd *extv1.CustomResourceDefinition, limit int) error {
+	size, err := EstimateSize(crd)
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		return errors.Errorf(errFmtTooLarge, size, limit)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestEstimateSize(t *testing.T) {
+	crd := &extv1.CustomResourceDefinition{
+		Spec: extv1.CustomResourceDefinitionSpec{
+			Group: "example.org",
+			Versions: []extv1.CustomResourceDefinitionVersion{{
+				Name:   "v1alpha1",
+				Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: wide(10)},
+			}},
+		},
+	}
+	j, _ := json.Marshal(crd)
+
+	got, err := EstimateSize(crd)
+	if err != nil {
+		t.Fatalf("EstimateSize(...): %s", err)
+	}
+	if diff := cmp.Diff(len(j), got); diff != "" {
+		t.Errorf("EstimateSize(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCheckSize(t *testing.T) {
+	crd := func(s *extv1.JSONSchemaProps) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Versions: []extv1.CustomResourceDefinitionVersion{{
+					Name:   "v1alpha1",
+					Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: s},
+				}},
+			},
+		}
+	}
+
+	small := crd(wide(100))
+	smallSize, _ := EstimateSize(small)
+
+	// Each property of a wide schema serializes to more than 20 bytes.
+	large := crd(wide(DefaultMaxSize / 20))
+	largeSize, _ := EstimateSize(large)
+
+	cases := map[string]struct {
+		reason string
+		crd    *extv1.CustomResourceDefinition
+		limit  int
+		want   error
+	}{
+		"WithinLimit": {
+			reason: "A CRD that is exactly as large as the limit should be accepted.",
+			crd:    small,
+			limit:  smallSize,
+		},
+		"TooLarge": {
+			reason: "A CRD that is larger than the limit should be rejected.",
+			crd:    small,
+			limit:  smallSize - 1,
+			want:   errors.Errorf(errFmtTooLarge, smallSize, smallSize-1),
+		},
+		"WithinDefaultLimit": {
+			reason: "A CRD that is smaller than the default limit should be accepted.",
+			crd:    small,
+			limit:  DefaultMaxSize,
+		},
+		"TooLargeForDefaultLimit": {
+			reason: "A CRD that is larger than the default limit should be rejected.",
+			crd:    large,
+			limit:  DefaultMaxSize,
+			want:   errors.Errorf(errFmtTooLarge, largeSize, DefaultMaxSize),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := CheckSize(tc.crd, tc.limit)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCheckSize(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+const (
+	errConvertSchema = "cannot convert validation schema"
+	errCompileSchema = "cannot compile validation schema"
+	errFmtNoSchema   = "version %q of CustomResourceDefinition %q has no validation schema"
+)
+
+// GenerateAndValidate generates the composite resource CRD, and the composite
+// resource claim CRD if one is offered, for the supplied XRD. It validates that
+// the schema of every version of each CRD is structural, as required by
+// api-server. All problems are returned together. No CRDs are returned if
+// they could not be generated, or if any problems were found.
+func GenerateAndValidate(xrd *v1alpha1.CompositeResourceDefinition, o ...Option) ([]*extv1.CustomResourceDefinition, field.ErrorList) {
+	composite, claim, err := ForXRD(xrd, o...)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(field.NewPath("spec"), xrd.GetName(), err.Error())}
+	}
+
+	crds := []*extv1.CustomResourceDefinition{composite}
+	if claim != nil {
+		crds = append(crds, claim)
+	}
+
+	errs := field.ErrorList{}
+	for _, crd := range crds {
+		errs = append(errs, ValidateStructural(crd)...)
+		errs = append(errs, ValidateEnums(crd)...)
+		errs = append(errs, ValidatePrinterColumns(crd)...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return crds, nil
+}
+
+// SchemaValidator returns a function that validates objects against the schema
+// of the supplied version of the supplied CRD, as api-server would. This allows
+// composite resources and claims to be validated without a cluster, e.g. in CI.
+// Paths in the errors returned by the validator are rooted at the object. An
+// error is returned if the CRD does not serve the version, or if the version
+// has no schema.
+func SchemaValidator(crd *extv1.CustomResourceDefinition, version string) (func(obj map[string]interface{}) field.ErrorList, error) {
+	var v *extv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Name == version && crd.Spec.Versions[i].Served {
+			v = &crd.Spec.Versions[i]
+		}
+	}
+	if v == nil {
+		return nil, errors.Errorf(errFmtVersionNotServed, version, crd.GetName())
+	}
+	if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+		return nil, errors.Errorf(errFmtNoSchema, version, crd.GetName())
+	}
+
+	validate, err := compileSchema(v.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(obj map[string]interface{}) field.ErrorList {
+		return validate(nil, obj)
+	}, nil
+}
+
+// ValidatesExisting validates the supplied existing objects, e.g. a sample of
+// the composite resources stored at the current storage version, against the
+// supplied new schema, as api-server would. This allows an XRD to be checked
+// before it is upgraded to a schema that existing objects would not conform
+// to. Paths in the returned errors are rooted at the index of each object.
+func ValidatesExisting(newSchema *extv1.JSONSchemaProps, existing []map[string]interface{}) field.ErrorList {
+	validate, err := compileSchema(&extv1.CustomResourceValidation{OpenAPIV3Schema: newSchema})
+	if err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("openAPIV3Schema"), "", err.Error())}
+	}
+
+	errs := field.ErrorList{}
+	for i, obj := range existing {
+		errs = append(errs, validate(field.NewPath("existing").Index(i), obj)...)
+	}
+	return errs
+}
+
+// compileSchema compiles the supplied schema into a function that validates
+// objects against it. Paths in the errors returned by that function are rooted
+// at the path supplied to it.
+func compileSchema(s *extv1.CustomResourceValidation) (func(p *field.Path, obj interface{}) field.ErrorList, error) {
+	internal := &apiextensions.CustomResourceValidation{}
+	if err := extv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(s, internal, nil); err != nil {
+		return nil, errors.Wrap(err, errConvertSchema)
+	}
+	sv, _, err := validation.NewSchemaValidator(internal)
+	if err != nil {
+		return nil, errors.Wrap(err, errCompileSchema)
+	}
+	return func(p *field.Path, obj interface{}) field.ErrorList {
+		return validation.ValidateCustomResource(p, obj, sv)
+	}, nil
+}
+
+// ValidateStructural validates that the schema of every version of the
+// supplied CRD is structural. Paths in the returned errors are rooted at the
+// name of the supplied CRD.
+func ValidateStructural(crd *extv1.CustomResourceDefinition) field.ErrorList {
+	errs := field.ErrorList{}
+	p := field.NewPath("crds").Key(crd.GetName()).Child("spec", "versions")
+	for i, v := range crd.Spec.Versions {
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		sp := p.Index(i).Child("schema", "openAPIV3Schema")
+
+		internal := &apiextensions.JSONSchemaProps{}
+		if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, internal, nil); err != nil {
+			errs = append(errs, field.Invalid(sp, "", err.Error()))
+			continue
+		}
+
+		s, err := schema.NewStructural(internal)
+		if err != nil {
+			errs = append(errs, field.Invalid(sp, "", err.Error()))
+			continue
+		}
+
+		errs = append(errs, schema.ValidateStructural(sp, s)...)
+	}
+	return errs
+}
+
+// ValidateEnums validates that the enum values of every property in the schema
+// of every version of the supplied CRD are compatible with the type of that
+// property, e.g. that an integer property only enumerates integers. Paths in the
+// returned errors are rooted at the name of the supplied CRD.
+func ValidateEnums(crd *extv1.CustomResourceDefinition) field.ErrorList {
+	errs := field.ErrorList{}
+	p := field.NewPath("crds").Key(crd.GetName()).Child("spec", "versions")
+	for i, v := range crd.Spec.Versions {
+		if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		errs = append(errs, validateEnums(p.Index(i).Child("schema", "openAPIV3Schema"), v.Schema.OpenAPIV3Schema)...)
+	}
+	return errs
+}
+
+func validateEnums(p *field.Path, root *extv1.JSONSchemaProps) field.ErrorList {
+	errs := field.ErrorList{}
+	_ = walkSchema(root, p, func(p *field.Path, s *extv1.JSONSchemaProps, _ int) error {
+		for i, e := range s.Enum {
+			// A null enum value is unmarshalled as an empty raw value.
+			raw := e.Raw
+			if len(raw) == 0 {
+				raw = []byte("null")
+			}
+			if !matchesType(raw, s.Type, s.Nullable) {
+				errs = append(errs, field.Invalid(p.Child("enum").Index(i), string(raw), fmt.Sprintf("must be of type %s", s.Type)))
+			}
+		}
+		return nil
+	})
+	return errs
+}
+
+// ValidatePrinterColumns validates that the JSONPath of every additional printer
+// column of every version of the supplied CRD is a simple JSON path that can be
+// parsed, e.g. .status.conditions[?(@.type=='Ready')].status. Malformed paths
+// would otherwise only be caught when the CRD is applied, or when its custom
+// resources are printed. Paths in the returned errors are rooted at the name of
+// the supplied CRD.
+func ValidatePrinterColumns(crd *extv1.CustomResourceDefinition) field.ErrorList {
+	errs := field.ErrorList{}
+	p := field.NewPath("crds").Key(crd.GetName()).Child("spec", "versions")
+	for i, v := range crd.Spec.Versions {
+		for j, col := range v.AdditionalPrinterColumns {
+			if detail := validateJSONPath(col.JSONPath); detail != "" {
+				errs = append(errs, field.Invalid(p.Index(i).Child("additionalPrinterColumns").Index(j).Child("jsonPath"), col.JSONPath, detail))
+			}
+		}
+	}
+	return errs
+}
+
+// validateJSONPath returns a description of why the supplied JSON path is not
+// a valid printer column path, or an empty string if it is valid.
+func validateJSONPath(path string) string {
+	if !strings.HasPrefix(path, ".") {
+		return "must be a simple JSON path starting with ."
+	}
+	jp, err := jsonpath.Parse("", "{"+path+"}")
+	if err != nil {
+		return err.Error()
+	}
+	if len(jp.Root.Nodes) != 1 {
+		return "must be a single JSON path expression"
+	}
+	for _, n := range jp.Root.Nodes[0].(*jsonpath.ListNode).Nodes {
+		switch n.Type() {
+		case jsonpath.NodeField, jsonpath.NodeArray, jsonpath.NodeFilter:
+		default:
+			return "must be a simple JSON path of fields, array indices, and filters"
+		}
+	}
+	return ""
+}
+
+// matchesType returns true if the supplied raw JSON value is of the supplied
+// OpenAPI type. Any value matches an empty type. A null value matches only if
+// the type is nullable.
+func matchesType(raw []byte, t string, nullable bool) bool {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return false
+	}
+	if v == nil {
+		return nullable
+	}
+
+	switch t {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	}
+	return true
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestGenerateAndValidate(t *testing.T) {
+	untyped := `{"properties":{"spec":{"properties":{"a":{},"b":{"properties":{"c":{"type":"string"}}}},"type":"object"}},"type":"object"}`
+
+	typeRequired := func(crd, prop string) *field.Error {
+		p := field.NewPath("crds").Key(crd).Child("spec", "versions").Index(0).Child("schema", "openAPIV3Schema", "properties").Key("spec").Child("properties").Key(prop).Child("type")
+		return field.Required(p, "must not be empty for specified object fields")
+	}
+
+	type want struct {
+		crds []string
+		errs field.ErrorList
+	}
+
+	cases := map[string]struct {
+		reason string
+		xrd    *v1alpha1.CompositeResourceDefinition
+		want   want
+	}{
+		"Valid": {
+			reason: "Both CRDs should be returned without errors when the XRD is valid.",
+			xrd:    minimalXRD(`{"properties":{"spec":{"properties":{"a":{"type":"string"}},"type":"object"}},"type":"object"}`),
+			want: want{
+				crds: []string{"coolcomposites.example.org", "coolclaims.example.org"},
+			},
+		},
+		"NotStructural": {
+			reason: "Every structural schema problem in every generated CRD should be returned.",
+			xrd:    minimalXRD(untyped),
+			want: want{
+				errs: field.ErrorList{
+					typeRequired("coolcomposites.example.org", "a"),
+					typeRequired("coolcomposites.example.org", "b"),
+					typeRequired("coolclaims.example.org", "a"),
+					typeRequired("coolclaims.example.org", "b"),
+				},
+			},
+		},
+		"CannotGenerate": {
+			reason: "An error should be returned if the CRDs cannot be generated.",
+			xrd:    &v1alpha1.CompositeResourceDefinition{},
+			want: want{
+				errs: field.ErrorList{
+					field.Invalid(field.NewPath("spec"), "", errors.Wrap(errors.Wrap(errors.New(errMissingVersions), errInvalidVersions), errGenerateComposite).Error()),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crds, errs := GenerateAndValidate(tc.xrd)
+			if diff := cmp.Diff(tc.want.errs, errs, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b *field.Error) bool { return a.Field < b.Field })); diff != "" {
+				t.Errorf("\n%s\nGenerateAndValidate(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+			var got []string
+			for _, crd := range crds {
+				got = append(got, crd.GetName())
+			}
+			if diff := cmp.Diff(tc.want.crds, got); diff != "" {
+				t.Errorf("\n%s\nGenerateAndValidate(...): -want CRDs, +got CRDs:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateEnums(t *testing.T) {
+	enum := func(prop string, i int) *field.Path {
+		return field.NewPath("crds").Key("coolcomposites.example.org").Child("spec", "versions").Index(0).Child("schema", "openAPIV3Schema", "properties").Key("spec").Child("properties").Key(prop).Child("enum").Index(i)
+	}
+
+	cases := map[string]struct {
+		reason string
+		schema string
+		want   field.ErrorList
+	}{
+		"Valid": {
+			reason: "Enums whose values match the type of their property should be valid.",
+			schema: `{"properties":{"spec":{"properties":{"i":{"type":"integer","enum":[1,2]},"b":{"type":"boolean","enum":[true]},"s":{"type":"string","enum":["a"]},"n":{"type":"number","enum":[1.5]}},"type":"object"}},"type":"object"}`,
+			want:   field.ErrorList{},
+		},
+		"NullableNull": {
+			reason: "A null enum value should be valid for a nullable property.",
+			schema: `{"properties":{"spec":{"properties":{"s":{"type":"string","nullable":true,"enum":["a",null]}},"type":"object"}},"type":"object"}`,
+			want:   field.ErrorList{},
+		},
+		"IntegerMismatch": {
+			reason: "String and fractional enum values should be invalid for an integer property.",
+			schema: `{"properties":{"spec":{"properties":{"i":{"type":"integer","enum":[1,"2",2.5]}},"type":"object"}},"type":"object"}`,
+			want: field.ErrorList{
+				field.Invalid(enum("i", 1), `"2"`, "must be of type integer"),
+				field.Invalid(enum("i", 2), `2.5`, "must be of type integer"),
+			},
+		},
+		"BooleanMismatch": {
+			reason: "String enum values should be invalid for a boolean property.",
+			schema: `{"properties":{"spec":{"properties":{"b":{"type":"boolean","enum":["true",false]}},"type":"object"}},"type":"object"}`,
+			want: field.ErrorList{
+				field.Invalid(enum("b", 0), `"true"`, "must be of type boolean"),
+			},
+		},
+		"NotNullableNull": {
+			reason: "A null enum value should be invalid for a property that is not nullable.",
+			schema: `{"properties":{"spec":{"properties":{"b":{"type":"boolean","enum":[null]}},"type":"object"}},"type":"object"}`,
+			want: field.ErrorList{
+				field.Invalid(enum("b", 0), `null`, "must be of type boolean"),
+			},
+		},
+		"NestedMismatch": {
+			reason: "Enums of array items should be validated.",
+			schema: `{"properties":{"spec":{"properties":{"a":{"type":"array","items":{"type":"integer","enum":["one"]}}},"type":"object"}},"type":"object"}`,
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("crds").Key("coolcomposites.example.org").Child("spec", "versions").Index(0).Child("schema", "openAPIV3Schema", "properties").Key("spec").Child("properties").Key("a").Child("items", "enum").Index(0), `"one"`, "must be of type integer"),
+			},
+		},
+		"CombinatorMismatch": {
+			reason: "Enums of schemas nested within combinators should be validated.",
+			schema: `{"properties":{"spec":{"properties":{"c":{"type":"object","anyOf":[{"properties":{"i":{"type":"integer","enum":["one"]}}}]}},"type":"object"}},"type":"object"}`,
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("crds").Key("coolcomposites.example.org").Child("spec", "versions").Index(0).Child("schema", "openAPIV3Schema", "properties").Key("spec").Child("properties").Key("c").Child("anyOf").Index(0).Child("properties").Key("i").Child("enum").Index(0), `"one"`, "must be of type integer"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			crd, err := ForCompositeResource(minimalXRD(tc.schema))
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			got := ValidateEnums(crd)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateEnums(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidatePrinterColumns(t *testing.T) {
+	jsonPath := func(col int) *field.Path {
+		return field.NewPath("crds").Key("coolcomposites.example.org").Child("spec", "versions").Index(0).Child("additionalPrinterColumns").Index(col).Child("jsonPath")
+	}
+
+	cases := map[string]struct {
+		reason string
+		cols   []extv1.CustomResourceColumnDefinition
+		want   field.ErrorList
+	}{
+		"InjectedColumns": {
+			reason: "The printer columns Crossplane injects should be valid.",
+		},
+		"ValidColumns": {
+			reason: "Simple JSON paths, including those with filters and indices, should be valid.",
+			cols: []extv1.CustomResourceColumnDefinition{
+				{Name: "REGION", Type: "string", JSONPath: ".spec.region"},
+				{Name: "ZONE", Type: "string", JSONPath: ".spec.zones[0]"},
+			},
+		},
+		"MalformedColumns": {
+			reason: "Every malformed JSON path should be returned.",
+			cols: []extv1.CustomResourceColumnDefinition{
+				{Name: "REGION", Type: "string", JSONPath: "spec.region"},
+				{Name: "ZONE", Type: "string", JSONPath: ".spec.zones["},
+				{Name: "CONDITIONS", Type: "string", JSONPath: ".status..conditions"},
+			},
+			want: field.ErrorList{
+				field.Invalid(jsonPath(0), "spec.region", "must be a simple JSON path starting with ."),
+				field.Invalid(jsonPath(1), ".spec.zones[", "unterminated array"),
+				field.Invalid(jsonPath(2), ".status..conditions", "must be a simple JSON path of fields, array indices, and filters"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := minimalXRD(`{}`)
+			d.Spec.Versions[0].AdditionalPrinterColumns = tc.cols
+			crd, err := ForCompositeResource(d)
+			if err != nil {
+				t.Fatalf("ForCompositeResource(...): %s", err)
+			}
+			got := ValidatePrinterColumns(crd)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nValidatePrinterColumns(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSchemaValidator(t *testing.T) {
+	schema := `{"properties":{"spec":{"properties":{"storageGB":{"type":"integer","minimum":1},"tier":{"type":"string","enum":["small","large"]}},"type":"object"}},"type":"object"}`
+
+	crd, err := ForCompositeResource(minimalXRD(schema))
+	if err != nil {
+		t.Fatalf("ForCompositeResource(...): %s", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    map[string]interface{}
+		want   field.ErrorList
+	}{
+		"Conforming": {
+			reason: "An object that conforms to the schema should be valid.",
+			obj: map[string]interface{}{
+				"apiVersion": "example.org/v1alpha1",
+				"kind":       "CoolComposite",
+				"spec": map[string]interface{}{
+					"storageGB": 20,
+					"tier":      "small",
+				},
+			},
+		},
+		"NonConforming": {
+			reason: "Every way in which an object does not conform to the schema should be returned.",
+			obj: map[string]interface{}{
+				"apiVersion": "example.org/v1alpha1",
+				"kind":       "CoolComposite",
+				"spec": map[string]interface{}{
+					"storageGB": "20",
+					"tier":      "medium",
+				},
+			},
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "storageGB"), "string", ""),
+				field.NotSupported(field.NewPath("spec", "tier"), "medium", []string{"small", "large"}),
+			},
+		},
+	}
+
+	validate, err := SchemaValidator(crd, "v1alpha1")
+	if err != nil {
+		t.Fatalf("SchemaValidator(...): %s", err)
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validate(tc.obj)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty(), cmpopts.IgnoreFields(field.Error{}, "BadValue", "Detail"), cmpopts.SortSlices(func(a, b *field.Error) bool { return a.Field < b.Field })); diff != "" {
+				t.Errorf("\n%s\nSchemaValidator(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSchemaValidatorUnknownVersion(t *testing.T) {
+	crd, err := ForCompositeResource(minimalXRD(`{}`))
+	if err != nil {
+		t.Fatalf("ForCompositeResource(...): %s", err)
+	}
+
+	want := errors.Errorf(errFmtVersionNotServed, "v2", "coolcomposites.example.org")
+	_, err = SchemaValidator(crd, "v2")
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("SchemaValidator(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestValidatesExisting(t *testing.T) {
+	s := &extv1.JSONSchemaProps{}
+	if err := json.Unmarshal([]byte(`{"type":"object","properties":{"spec":{"type":"object","properties":{"storageGB":{"type":"integer","minimum":10},"tier":{"type":"string","enum":["small","large"]}}}}}`), s); err != nil {
+		t.Fatalf("json.Unmarshal(...): %s", err)
+	}
+
+	cases := map[string]struct {
+		reason   string
+		existing []map[string]interface{}
+		want     field.ErrorList
+	}{
+		"Compatible": {
+			reason: "Existing objects that conform to the new schema should produce no errors.",
+			existing: []map[string]interface{}{
+				{"spec": map[string]interface{}{"storageGB": 20, "tier": "small"}},
+				{"spec": map[string]interface{}{"storageGB": 10}},
+			},
+		},
+		"Incompatible": {
+			reason: "Every way in which each existing object does not conform to the new schema should be returned.",
+			existing: []map[string]interface{}{
+				{"spec": map[string]interface{}{"storageGB": 20, "tier": "small"}},
+				{"spec": map[string]interface{}{"storageGB": 5, "tier": "medium"}},
+			},
+			want: field.ErrorList{
+				field.Invalid(field.NewPath("existing").Index(1).Child("spec", "storageGB"), 5, ""),
+				field.NotSupported(field.NewPath("existing").Index(1).Child("spec", "tier"), "medium", []string{"small", "large"}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ValidatesExisting(s, tc.existing)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty(), cmpopts.IgnoreFields(field.Error{}, "BadValue", "Detail"), cmpopts.SortSlices(func(a, b *field.Error) bool { return a.Field < b.Field })); diff != "" {
+				t.Errorf("\n%s\nValidatesExisting(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// forEachSubschema calls the supplied function for each schema nested directly
+// within the supplied schema, along with its path. The supplied path is that of
+// the supplied schema, and may be nil. Properties and pattern properties are
+// stored by value, so the function is passed a copy of each, which is written
+// back once the function returns. forEachSubschema stops at the first error the
+// function returns, and returns it.
+func forEachSubschema(s *extv1.JSONSchemaProps, p *field.Path, fn func(p *field.Path, s *extv1.JSONSchemaProps) error) error {
+	for _, m := range []struct {
+		name  string
+		props map[string]extv1.JSONSchemaProps
+	}{{"properties", s.Properties}, {"patternProperties", s.PatternProperties}} {
+		for k := range m.props {
+			v := m.props[k]
+			if err := fn(p.Child(m.name).Key(k), &v); err != nil {
+				return err
+			}
+			m.props[k] = v
+		}
+	}
+	for _, n := range nestedSchemas(s, p) {
+		if err := fn(n.path, n.schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A nestedSchema is a schema nested within another, and its path.
+type nestedSchema struct {
+	path   *field.Path
+	schema *extv1.JSONSchemaProps
+}
+
+// nestedSchemas returns the schemas nested directly within the supplied schema
+// at the supplied path, other than its properties and pattern properties.
+func nestedSchemas(s *extv1.JSONSchemaProps, p *field.Path) []nestedSchema {
+	var out []nestedSchema
+	for _, l := range []struct {
+		name    string
+		schemas []extv1.JSONSchemaProps
+	}{{"allOf", s.AllOf}, {"anyOf", s.AnyOf}, {"oneOf", s.OneOf}} {
+		for i := range l.schemas {
+			out = append(out, nestedSchema{path: p.Child(l.name).Index(i), schema: &l.schemas[i]})
+		}
+	}
+	if s.Not != nil {
+		out = append(out, nestedSchema{path: p.Child("not"), schema: s.Not})
+	}
+	if s.Items != nil {
+		if s.Items.Schema != nil {
+			out = append(out, nestedSchema{path: p.Child("items"), schema: s.Items.Schema})
+		}
+		for i := range s.Items.JSONSchemas {
+			out = append(out, nestedSchema{path: p.Child("items").Index(i), schema: &s.Items.JSONSchemas[i]})
+		}
+	}
+	for _, pb := range []struct {
+		name   string
+		schema *extv1.JSONSchemaPropsOrBool
+	}{{"additionalProperties", s.AdditionalProperties}, {"additionalItems", s.AdditionalItems}} {
+		if pb.schema != nil && pb.schema.Schema != nil {
+			out = append(out, nestedSchema{path: p.Child(pb.name), schema: pb.schema.Schema})
+		}
+	}
+	return out
+}
+
+// walkSchema calls the supplied function for the supplied schema and for every
+// schema nested within it, along with the path and depth of each schema. The
+// supplied schema is at the supplied path, which may be nil, and is one level
+// deep. Each schema is passed to the function before the schemas nested within
+// it. walkSchema stops at the first error the function returns, and returns it.
+func walkSchema(s *extv1.JSONSchemaProps, p *field.Path, fn func(p *field.Path, s *extv1.JSONSchemaProps, depth int) error) error {
+	return walkSchemaAt(s, p, 1, fn)
+}
+
+func walkSchemaAt(s *extv1.JSONSchemaProps, p *field.Path, depth int, fn func(p *field.Path, s *extv1.JSONSchemaProps, depth int) error) error {
+	if err := fn(p, s, depth); err != nil {
+		return err
+	}
+	return forEachSubschema(s, p, func(p *field.Path, c *extv1.JSONSchemaProps) error {
+		return walkSchemaAt(c, p, depth+1, fn)
+	})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccrd
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestWalkSchema(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	s := func() *extv1.JSONSchemaProps {
+		return &extv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{
+				"a": {Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}}},
+			},
+			PatternProperties:    map[string]extv1.JSONSchemaProps{"^b": {Type: "string"}},
+			AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &extv1.JSONSchemaProps{Type: "string"}},
+			AllOf:                []extv1.JSONSchemaProps{{Type: "object"}},
+			AnyOf:                []extv1.JSONSchemaProps{{Type: "object"}},
+			OneOf:                []extv1.JSONSchemaProps{{Type: "object"}},
+			Not:                  &extv1.JSONSchemaProps{Type: "object", Not: &extv1.JSONSchemaProps{Type: "string"}},
+		}
+	}
+
+	type visit struct {
+		Path  string
+		Depth int
+	}
+
+	type want struct {
+		visits []visit
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"VisitsEverySchema": {
+			reason: "Every nested schema should be visited, with its path and depth.",
+			want: want{
+				visits: []visit{
+					{Path: "", Depth: 1},
+					{Path: "additionalProperties", Depth: 2},
+					{Path: "allOf[0]", Depth: 2},
+					{Path: "anyOf[0]", Depth: 2},
+					{Path: "not", Depth: 2},
+					{Path: "not.not", Depth: 3},
+					{Path: "oneOf[0]", Depth: 2},
+					{Path: "patternProperties[^b]", Depth: 2},
+					{Path: "properties[a]", Depth: 2},
+					{Path: "properties[a].items", Depth: 3},
+				},
+			},
+		},
+		"StopsAtError": {
+			reason: "The walk should stop at, and return, the first error.",
+			err:    errBoom,
+			want: want{
+				visits: []visit{{Path: "", Depth: 1}},
+				err:    errBoom,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var visits []visit
+			err := walkSchema(s(), nil, func(p *field.Path, _ *extv1.JSONSchemaProps, depth int) error {
+				visits = append(visits, visit{Path: p.String(), Depth: depth})
+				return tc.err
+			})
+			sort.Slice(visits, func(i, j int) bool { return visits[i].Path < visits[j].Path })
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nwalkSchema(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.visits, visits); diff != "" {
+				t.Errorf("\n%s\nwalkSchema(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("WritesBackProperties", func(t *testing.T) {
+		got := s()
+		_ = walkSchema(got, nil, func(_ *field.Path, s *extv1.JSONSchemaProps, _ int) error {
+			s.Description = "cool"
+			return nil
+		})
+		if diff := cmp.Diff("cool", got.Properties["a"].Items.Schema.Description); diff != "" {
+			t.Errorf("walkSchema(...): -want, +got:\n%s", diff)
+		}
+		if diff := cmp.Diff("cool", got.PatternProperties["^b"].Description); diff != "" {
+			t.Errorf("walkSchema(...): -want, +got:\n%s", diff)
+		}
+	})
+}
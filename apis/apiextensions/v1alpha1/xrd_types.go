@@ -78,6 +78,12 @@ type CompositeResourceDefinitionSpec struct {
 	// versions must have identical schemas; Crossplane does not currently
 	// support conversion between different version schemas.
 	Versions []CompositeResourceDefinitionVersion `json:"versions"`
+
+	// Conversion defines all conversion settings for the defined composite
+	// resource and its claim, if any. The conversionReviewVersions of a
+	// webhook conversion default to ["v1"] if they are not specified.
+	// +optional
+	Conversion *extv1.CustomResourceConversion `json:"conversion,omitempty"`
 }
 
 // CompositeResourceDefinitionVersion describes a version of an XR.
@@ -106,6 +112,14 @@ type CompositeResourceDefinitionVersion struct {
 	// +optional
 	Schema *CompositeResourceValidation `json:"schema,omitempty"`
 
+	// SchemaOverlays are merged over Schema, in order, to produce the schema
+	// of this version of the defined composite resource. Each overlay is
+	// deeply merged with the schema that precedes it; properties defined by
+	// later overlays override equivalently named properties defined earlier.
+	// An overlay may not change the type of a property.
+	// +optional
+	SchemaOverlays []CompositeResourceValidation `json:"schemaOverlays,omitempty"`
+
 	// AdditionalPrinterColumns specifies additional columns returned in Table
 	// output. If no columns are specified, a single column displaying the age
 	// of the custom resource is used. See the following link for details:
@@ -168,6 +168,11 @@ func (in *CompositeResourceDefinitionSpec) DeepCopyInto(out *CompositeResourceDe
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conversion != nil {
+		in, out := &in.Conversion, &out.Conversion
+		*out = new(v1.CustomResourceConversion)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeResourceDefinitionSpec.
@@ -205,6 +210,13 @@ func (in *CompositeResourceDefinitionVersion) DeepCopyInto(out *CompositeResourc
 		*out = new(CompositeResourceValidation)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SchemaOverlays != nil {
+		in, out := &in.SchemaOverlays, &out.SchemaOverlays
+		*out = make([]CompositeResourceValidation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AdditionalPrinterColumns != nil {
 		in, out := &in.AdditionalPrinterColumns, &out.AdditionalPrinterColumns
 		*out = make([]v1.CustomResourceColumnDefinition, len(*in))
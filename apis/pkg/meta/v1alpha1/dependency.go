@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errFmtDecodeDependency  = "cannot decode dependency %d"
+	errFmtInvalidDependency = "invalid dependency %d"
+	errNoPackage            = "one of provider or configuration must be specified"
+	errMultiplePackages     = "only one of provider or configuration may be specified"
+	errNoVersion            = "version must be specified"
+)
+
+// ParseDependencies parses a stream of JSON-encoded dependencies read from the
+// supplied reader. Each dependency is expected to be a JSON object with only
+// the fields of a Dependency; objects may be separated by newlines or any other
+// whitespace. An error is returned if any dependency cannot be decoded, or does
+// not specify exactly one package and a version.
+func ParseDependencies(r io.Reader) ([]Dependency, error) {
+	var deps []Dependency
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	for i := 0; ; i++ {
+		dep := Dependency{}
+		err := d.Decode(&dep)
+		if err == io.EOF {
+			return deps, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtDecodeDependency, i)
+		}
+		if err := validateDependency(dep); err != nil {
+			return nil, errors.Wrapf(err, errFmtInvalidDependency, i)
+		}
+		deps = append(deps, dep)
+	}
+}
+
+func validateDependency(d Dependency) error {
+	switch {
+	case d.Provider == nil && d.Configuration == nil:
+		return errors.New(errNoPackage)
+	case d.Provider != nil && d.Configuration != nil:
+		return errors.New(errMultiplePackages)
+	case d.Version == "":
+		return errors.New(errNoVersion)
+	}
+	return nil
+}
+
+// IsProvider returns true if this dependency is on a Provider package, rather
+// than a Configuration package.
+func (d Dependency) IsProvider() bool {
+	return d.Provider != nil
+}
+
+// PackageName returns the name of the package this dependency depends on.
+func (d Dependency) PackageName() string {
+	if d.Provider != nil {
+		return *d.Provider
+	}
+	if d.Configuration != nil {
+		return *d.Configuration
+	}
+	return ""
+}
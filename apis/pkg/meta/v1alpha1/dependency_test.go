@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestParseDependencies(t *testing.T) {
+	provider := "crossplane/provider-aws"
+	configuration := "crossplane/getting-started-with-aws"
+
+	type want struct {
+		deps []Dependency
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		input  string
+		want   want
+	}{
+		"Empty": {
+			reason: "Empty input should produce no dependencies.",
+			input:  "",
+			want:   want{},
+		},
+		"WellFormed": {
+			reason: "Each well-formed JSON object should be parsed as a dependency.",
+			input: `{"provider": "crossplane/provider-aws", "version": ">=v0.1.0"}
+{"configuration": "crossplane/getting-started-with-aws", "version": "v0.2.0"}
+`,
+			want: want{deps: []Dependency{
+				{Provider: &provider, Version: ">=v0.1.0"},
+				{Configuration: &configuration, Version: "v0.2.0"},
+			}},
+		},
+		"Malformed": {
+			reason: "An error should be returned if a dependency cannot be decoded.",
+			input: `{"provider": "crossplane/provider-aws", "version": ">=v0.1.0"}
+{"configuration": `,
+			want: want{err: errors.Wrapf(errors.New("unexpected EOF"), errFmtDecodeDependency, 1)},
+		},
+		"MissingPackage": {
+			reason: "An error should be returned if a dependency specifies neither a provider nor a configuration.",
+			input:  `{"version": ">=v0.1.0"}`,
+			want:   want{err: errors.Wrapf(errors.New(errNoPackage), errFmtInvalidDependency, 0)},
+		},
+		"MultiplePackages": {
+			reason: "An error should be returned if a dependency specifies both a provider and a configuration.",
+			input:  `{"provider": "crossplane/provider-aws", "configuration": "crossplane/getting-started-with-aws", "version": "v0.2.0"}`,
+			want:   want{err: errors.Wrapf(errors.New(errMultiplePackages), errFmtInvalidDependency, 0)},
+		},
+		"MissingVersion": {
+			reason: "An error should be returned if a dependency does not specify a version.",
+			input:  `{"provider": "crossplane/provider-aws"}`,
+			want:   want{err: errors.Wrapf(errors.New(errNoVersion), errFmtInvalidDependency, 0)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseDependencies(strings.NewReader(tc.input))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseDependencies(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deps, got); diff != "" {
+				t.Errorf("\n%s\nParseDependencies(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsProvider(t *testing.T) {
+	pkg := "crossplane/provider-aws"
+
+	cases := map[string]struct {
+		reason string
+		dep    Dependency
+		want   bool
+	}{
+		"Provider": {
+			reason: "A dependency on a Provider should be a Provider dependency.",
+			dep:    Dependency{Provider: &pkg, Version: ">=v0.1.0"},
+			want:   true,
+		},
+		"Configuration": {
+			reason: "A dependency on a Configuration should not be a Provider dependency.",
+			dep:    Dependency{Configuration: &pkg, Version: ">=v0.1.0"},
+			want:   false,
+		},
+		"Unset": {
+			reason: "A dependency on neither kind of package should not be a Provider dependency.",
+			dep:    Dependency{Version: ">=v0.1.0"},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.dep.IsProvider()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsProvider(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	provider := "crossplane/provider-aws"
+	configuration := "crossplane/getting-started-with-aws"
+
+	cases := map[string]struct {
+		reason string
+		dep    Dependency
+		want   string
+	}{
+		"Provider": {
+			reason: "The name of a Provider dependency should be the name of the Provider.",
+			dep:    Dependency{Provider: &provider, Version: ">=v0.1.0"},
+			want:   provider,
+		},
+		"Configuration": {
+			reason: "The name of a Configuration dependency should be the name of the Configuration.",
+			dep:    Dependency{Configuration: &configuration, Version: ">=v0.1.0"},
+			want:   configuration,
+		},
+		"Unset": {
+			reason: "A dependency on neither kind of package should have an empty name.",
+			dep:    Dependency{Version: ">=v0.1.0"},
+			want:   "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.dep.PackageName()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPackageName(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
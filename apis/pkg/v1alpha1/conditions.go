@@ -34,11 +34,12 @@ const (
 
 // Reasons a package is or is not installed.
 const (
-	ReasonUnpacking runtimev1alpha1.ConditionReason = "UnpackingPackage"
-	ReasonInactive  runtimev1alpha1.ConditionReason = "InactivePackageRevision"
-	ReasonActive    runtimev1alpha1.ConditionReason = "ActivePackageRevision"
-	ReasonUnhealthy runtimev1alpha1.ConditionReason = "UnhealthyPackageRevision"
-	ReasonHealthy   runtimev1alpha1.ConditionReason = "HealthyPackageRevision"
+	ReasonUnpacking  runtimev1alpha1.ConditionReason = "UnpackingPackage"
+	ReasonInstalling runtimev1alpha1.ConditionReason = "InstallingPackageRevision"
+	ReasonInactive   runtimev1alpha1.ConditionReason = "InactivePackageRevision"
+	ReasonActive     runtimev1alpha1.ConditionReason = "ActivePackageRevision"
+	ReasonUnhealthy  runtimev1alpha1.ConditionReason = "UnhealthyPackageRevision"
+	ReasonHealthy    runtimev1alpha1.ConditionReason = "HealthyPackageRevision"
 )
 
 // Unpacking indicates that the package manager is waiting for a package
@@ -52,6 +53,17 @@ func Unpacking() runtimev1alpha1.Condition {
 	}
 }
 
+// Installing indicates that the package manager is installing the objects
+// of a package revision.
+func Installing() runtimev1alpha1.Condition {
+	return runtimev1alpha1.Condition{
+		Type:               TypeInstalled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonInstalling,
+	}
+}
+
 // Inactive indicates that the package manager is waiting for a package
 // revision to be transitioned to an active state.
 func Inactive() runtimev1alpha1.Condition {
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+)
+
+func TestConditions(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		c      runtimev1alpha1.Condition
+		want   runtimev1alpha1.Condition
+	}{
+		"Unpacking": {
+			reason: "Unpacking should indicate that a package is not yet installed.",
+			c:      Unpacking(),
+			want:   runtimev1alpha1.Condition{Type: TypeInstalled, Status: corev1.ConditionFalse, Reason: ReasonUnpacking},
+		},
+		"Installing": {
+			reason: "Installing should indicate that a package revision is not yet installed.",
+			c:      Installing(),
+			want:   runtimev1alpha1.Condition{Type: TypeInstalled, Status: corev1.ConditionFalse, Reason: ReasonInstalling},
+		},
+		"Inactive": {
+			reason: "Inactive should indicate that a package revision is not installed.",
+			c:      Inactive(),
+			want:   runtimev1alpha1.Condition{Type: TypeInstalled, Status: corev1.ConditionFalse, Reason: ReasonInactive},
+		},
+		"Active": {
+			reason: "Active should indicate that a package revision is installed.",
+			c:      Active(),
+			want:   runtimev1alpha1.Condition{Type: TypeInstalled, Status: corev1.ConditionTrue, Reason: ReasonActive},
+		},
+		"Unhealthy": {
+			reason: "Unhealthy should indicate that a package revision is not healthy.",
+			c:      Unhealthy(),
+			want:   runtimev1alpha1.Condition{Type: TypeHealthy, Status: corev1.ConditionFalse, Reason: ReasonUnhealthy},
+		},
+		"Healthy": {
+			reason: "Healthy should indicate that a package revision is healthy.",
+			c:      Healthy(),
+			want:   runtimev1alpha1.Condition{Type: TypeHealthy, Status: corev1.ConditionTrue, Reason: ReasonHealthy},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.c, cmpopts.IgnoreFields(runtimev1alpha1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\n%s(): -want, +got:\n%s", tc.reason, name, diff)
+			}
+		})
+	}
+}
@@ -21,7 +21,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	pkgmeta "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
 )
 
 // RevisionActivationPolicy indicates how a package should activate its
@@ -293,6 +296,9 @@ type PackageRevision interface {
 	GetObjects() []runtimev1alpha1.TypedReference
 	SetObjects(c []runtimev1alpha1.TypedReference)
 
+	GetDependencies() []pkgmeta.Dependency
+	SetDependencies(d []pkgmeta.Dependency)
+
 	GetControllerReference() runtimev1alpha1.Reference
 	SetControllerReference(c runtimev1alpha1.Reference)
 
@@ -316,6 +322,9 @@ type PackageRevision interface {
 
 	GetRevision() int64
 	SetRevision(r int64)
+
+	GetParent() string
+	SetParent(name string)
 }
 
 // GetCondition of this ProviderRevision.
@@ -338,6 +347,16 @@ func (p *ProviderRevision) SetObjects(c []runtimev1alpha1.TypedReference) {
 	p.Status.ObjectRefs = c
 }
 
+// GetDependencies of this ProviderRevision.
+func (p *ProviderRevision) GetDependencies() []pkgmeta.Dependency {
+	return p.Status.DependsOn
+}
+
+// SetDependencies of this ProviderRevision.
+func (p *ProviderRevision) SetDependencies(d []pkgmeta.Dependency) {
+	p.Status.DependsOn = d
+}
+
 // GetControllerReference of this ProviderRevision.
 func (p *ProviderRevision) GetControllerReference() runtimev1alpha1.Reference {
 	return p.Status.ControllerRef
@@ -398,6 +417,16 @@ func (p *ProviderRevision) SetRevision(r int64) {
 	p.Spec.Revision = r
 }
 
+// GetParent returns the name of the parent package of this ProviderRevision.
+func (p *ProviderRevision) GetParent() string {
+	return p.GetLabels()[LabelParentPackage]
+}
+
+// SetParent sets the name of the parent package of this ProviderRevision.
+func (p *ProviderRevision) SetParent(name string) {
+	meta.AddLabels(p, map[string]string{LabelParentPackage: name})
+}
+
 // GetIgnoreCrossplaneConstraints of this ProviderRevision.
 func (p *ProviderRevision) GetIgnoreCrossplaneConstraints() *bool {
 	return p.Spec.IgnoreCrossplaneConstraints
@@ -438,6 +467,16 @@ func (p *ConfigurationRevision) SetObjects(c []runtimev1alpha1.TypedReference) {
 	p.Status.ObjectRefs = c
 }
 
+// GetDependencies of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetDependencies() []pkgmeta.Dependency {
+	return p.Status.DependsOn
+}
+
+// SetDependencies of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetDependencies(d []pkgmeta.Dependency) {
+	p.Status.DependsOn = d
+}
+
 // GetControllerReference of this ConfigurationRevision.
 func (p *ConfigurationRevision) GetControllerReference() runtimev1alpha1.Reference {
 	return p.Status.ControllerRef
@@ -498,6 +537,16 @@ func (p *ConfigurationRevision) SetRevision(r int64) {
 	p.Spec.Revision = r
 }
 
+// GetParent returns the name of the parent package of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetParent() string {
+	return p.GetLabels()[LabelParentPackage]
+}
+
+// SetParent sets the name of the parent package of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetParent(name string) {
+	meta.AddLabels(p, map[string]string{LabelParentPackage: name})
+}
+
 // GetIgnoreCrossplaneConstraints of this ConfigurationRevision.
 func (p *ConfigurationRevision) GetIgnoreCrossplaneConstraints() *bool {
 	return p.Spec.IgnoreCrossplaneConstraints
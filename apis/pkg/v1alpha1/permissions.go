@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const errPermissionNotAllowed = "requested permission is not covered by any allowed policy rule"
+
+// ValidatePermissionRequests returns an error for each of the supplied
+// requested policy rules that is not covered by at least one of the supplied
+// allowed policy rules. A requested rule is covered by an allowed rule when
+// every verb, API group, resource, resource name, and non-resource URL it
+// requests is also granted by the allowed rule. A wildcard is only covered by
+// a wildcard; requesting '*' verbs is not covered by an allowed rule that
+// enumerates verbs.
+func ValidatePermissionRequests(rules []rbacv1.PolicyRule, allowed []rbacv1.PolicyRule) field.ErrorList {
+	errs := field.ErrorList{}
+	p := field.NewPath("permissionRequests")
+	for i, r := range rules {
+		if !allowedBy(r, allowed) {
+			errs = append(errs, field.Forbidden(p.Index(i), errPermissionNotAllowed))
+		}
+	}
+	return errs
+}
+
+// ToRole renders the supplied requested policy rules as a Role with the
+// supplied namespace and name, controlled by the supplied owner. It is intended
+// for packages whose controllers only require namespaced permissions. Requests
+// for non-resource URLs are omitted, because Roles cannot grant them.
+func ToRole(rules []rbacv1.PolicyRule, namespace, name string, owner metav1.OwnerReference) *rbacv1.Role {
+	r := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+	}
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			continue
+		}
+		r.Rules = append(r.Rules, *rule.DeepCopy())
+	}
+	return r
+}
+
+func allowedBy(r rbacv1.PolicyRule, allowed []rbacv1.PolicyRule) bool {
+	for _, a := range allowed {
+		if covers(a, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// covers returns true if the allowed rule a grants everything requested by r.
+func covers(a, r rbacv1.PolicyRule) bool {
+	if !coveredBy(r.Verbs, a.Verbs) {
+		return false
+	}
+
+	// A rule grants either resource or non-resource permissions. Rules that
+	// request non-resource URLs can only be covered by rules that grant them.
+	if len(r.NonResourceURLs) > 0 {
+		return coveredBy(r.NonResourceURLs, a.NonResourceURLs)
+	}
+
+	if !coveredBy(r.APIGroups, a.APIGroups) || !coveredBy(r.Resources, a.Resources) {
+		return false
+	}
+
+	// An allowed rule that does not restrict resource names grants access to
+	// all resource names.
+	if len(a.ResourceNames) == 0 {
+		return true
+	}
+
+	// A requested rule that does not restrict resource names requests access
+	// to all resource names, which a restricted allowed rule does not grant.
+	if len(r.ResourceNames) == 0 {
+		return false
+	}
+
+	return coveredBy(r.ResourceNames, a.ResourceNames)
+}
+
+// coveredBy returns true if every requested value is also an allowed value, or
+// if the allowed values include a wildcard.
+func coveredBy(requested, allowed []string) bool {
+	set := make(map[string]bool, len(allowed))
+	for _, v := range allowed {
+		if v == rbacv1.ResourceAll {
+			return true
+		}
+		set[v] = true
+	}
+	for _, v := range requested {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// PermissionDiff returns the policy rules that are in new but not old, and the
+// policy rules that are in old but not new. Rules are compared after they are
+// normalized, such that two rules that differ only in the order or repetition
+// of their verbs, API groups, resources, resource names, or non-resource URLs
+// are considered equal. Rules are returned as supplied, once each, in the
+// order they were supplied.
+func PermissionDiff(old, new []rbacv1.PolicyRule) (added, removed []rbacv1.PolicyRule) {
+	return difference(new, old), difference(old, new)
+}
+
+// difference returns the rules in a that are not in b.
+func difference(a, b []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	exclude := make(map[string]bool, len(b))
+	for _, r := range b {
+		exclude[ruleKey(r)] = true
+	}
+
+	var diff []rbacv1.PolicyRule
+	for _, r := range a {
+		k := ruleKey(r)
+		if exclude[k] {
+			continue
+		}
+		exclude[k] = true
+		diff = append(diff, r)
+	}
+	return diff
+}
+
+// ruleKey returns a key that uniquely identifies the normalized form of the
+// supplied rule.
+func ruleKey(r rbacv1.PolicyRule) string {
+	fields := [][]string{r.Verbs, r.APIGroups, r.Resources, r.ResourceNames, r.NonResourceURLs}
+	k := make([]string, len(fields))
+	for i, f := range fields {
+		k[i] = strings.Join(normalize(f), "\x00")
+	}
+	return strings.Join(k, "\x01")
+}
+
+// normalize returns a sorted copy of the supplied values without duplicates.
+func normalize(values []string) []string {
+	set := make(map[string]bool, len(values))
+	n := make([]string, 0, len(values))
+	for _, v := range values {
+		if set[v] {
+			continue
+		}
+		set[v] = true
+		n = append(n, v)
+	}
+	sort.Strings(n)
+	return n
+}
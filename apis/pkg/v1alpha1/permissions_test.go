@@ -0,0 +1,248 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidatePermissionRequests(t *testing.T) {
+	allowed := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"example.org"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets", "configmaps"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{"cool-secret"},
+			Verbs:         []string{"update"},
+		},
+		{
+			NonResourceURLs: []string{"/healthz"},
+			Verbs:           []string{"get"},
+		},
+	}
+
+	p := field.NewPath("permissionRequests")
+
+	cases := map[string]struct {
+		reason string
+		rules  []rbacv1.PolicyRule
+		want   field.ErrorList
+	}{
+		"Permitted": {
+			reason: "Rules covered by the allowed rules should be permitted.",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"example.org"}, Resources: []string{"coolresources"}, Verbs: []string{"*"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "watch"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"cool-secret"}, Verbs: []string{"update"}},
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			},
+			want: field.ErrorList{},
+		},
+		"DeniedVerbs": {
+			reason: "Rules that request verbs not granted by any allowed rule should be denied.",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "delete"}},
+			},
+			want: field.ErrorList{field.Forbidden(p.Index(1), errPermissionNotAllowed)},
+		},
+		"WildcardEscalation": {
+			reason: "Rules that request wildcards not granted by any allowed rule should be denied.",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"*"}},
+				{APIGroups: []string{"*"}, Resources: []string{"coolresources"}, Verbs: []string{"get"}},
+			},
+			want: field.ErrorList{
+				field.Forbidden(p.Index(0), errPermissionNotAllowed),
+				field.Forbidden(p.Index(1), errPermissionNotAllowed),
+			},
+		},
+		"UnrestrictedResourceNames": {
+			reason: "Rules that request all resource names should not be covered by allowed rules restricted to specific names.",
+			rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"update"}},
+			},
+			want: field.ErrorList{field.Forbidden(p.Index(0), errPermissionNotAllowed)},
+		},
+		"DeniedNonResourceURLs": {
+			reason: "Rules that request non-resource URLs not granted by any allowed rule should be denied.",
+			rules: []rbacv1.PolicyRule{
+				{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+			},
+			want: field.ErrorList{field.Forbidden(p.Index(0), errPermissionNotAllowed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ValidatePermissionRequests(tc.rules, allowed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidatePermissionRequests(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestToRole(t *testing.T) {
+	owner := metav1.OwnerReference{
+		APIVersion: "pkg.crossplane.io/v1alpha1",
+		Kind:       "ProviderRevision",
+		Name:       "provider-example-1234",
+		UID:        "no-you-id",
+	}
+	secrets := rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"secrets"},
+		Verbs:     []string{"get", "list", "watch"},
+	}
+
+	cases := map[string]struct {
+		reason string
+		rules  []rbacv1.PolicyRule
+		want   *rbacv1.Role
+	}{
+		"NoPermissionRequests": {
+			reason: "A Role without rules should be returned when no permissions are requested.",
+			want: &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       "crossplane-system",
+					Name:            "provider-example",
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+			},
+		},
+		"PermissionRequests": {
+			reason: "Requested resource permissions should be rendered as Role rules, omitting non-resource URLs.",
+			rules: []rbacv1.PolicyRule{
+				secrets,
+				{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+			},
+			want: &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       "crossplane-system",
+					Name:            "provider-example",
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+				Rules: []rbacv1.PolicyRule{secrets},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ToRole(tc.rules, "crossplane-system", "provider-example", owner)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nToRole(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPermissionDiff(t *testing.T) {
+	secrets := rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"secrets"},
+		Verbs:     []string{"get", "list", "watch"},
+	}
+	secretsReordered := rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"secrets", "secrets"},
+		Verbs:     []string{"watch", "get", "list"},
+	}
+	configMaps := rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+		Verbs:     []string{"get"},
+	}
+	events := rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"events"},
+		Verbs:     []string{"create", "update"},
+	}
+
+	type want struct {
+		added   []rbacv1.PolicyRule
+		removed []rbacv1.PolicyRule
+	}
+
+	cases := map[string]struct {
+		reason string
+		old    []rbacv1.PolicyRule
+		new    []rbacv1.PolicyRule
+		want   want
+	}{
+		"Unchanged": {
+			reason: "No rules should be added or removed when the rules are unchanged.",
+			old:    []rbacv1.PolicyRule{secrets, configMaps},
+			new:    []rbacv1.PolicyRule{configMaps, secrets},
+			want:   want{},
+		},
+		"Normalized": {
+			reason: "Rules that differ only in the order or repetition of their values should be considered unchanged.",
+			old:    []rbacv1.PolicyRule{secrets},
+			new:    []rbacv1.PolicyRule{secretsReordered},
+			want:   want{},
+		},
+		"Added": {
+			reason: "Rules that are only in the new rules should be added.",
+			old:    []rbacv1.PolicyRule{secrets},
+			new:    []rbacv1.PolicyRule{secrets, events, events},
+			want:   want{added: []rbacv1.PolicyRule{events}},
+		},
+		"Removed": {
+			reason: "Rules that are only in the old rules should be removed.",
+			old:    []rbacv1.PolicyRule{secrets, configMaps},
+			new:    []rbacv1.PolicyRule{secrets},
+			want:   want{removed: []rbacv1.PolicyRule{configMaps}},
+		},
+		"AddedAndRemoved": {
+			reason: "Rules may be both added and removed.",
+			old:    []rbacv1.PolicyRule{secrets, configMaps},
+			new:    []rbacv1.PolicyRule{secrets, events},
+			want: want{
+				added:   []rbacv1.PolicyRule{events},
+				removed: []rbacv1.PolicyRule{configMaps},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			added, removed := PermissionDiff(tc.old, tc.new)
+			if diff := cmp.Diff(tc.want.added, added); diff != "" {
+				t.Errorf("\n%s\nPermissionDiff(...): -want added, +got added:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.removed, removed); diff != "" {
+				t.Errorf("\n%s\nPermissionDiff(...): -want removed, +got removed:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
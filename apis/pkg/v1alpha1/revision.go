@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+)
+
+const errRevisionNotPositive = "revision must be at least 1"
+
+// SortByRevision sorts the supplied package revisions in place, in ascending
+// order of revision number. Revisions with equal revision numbers are sorted by
+// name.
+func SortByRevision(revs []PackageRevision) {
+	sort.SliceStable(revs, func(i, j int) bool {
+		return revisionLess(revs[i], revs[j])
+	})
+}
+
+// Latest returns the package revision with the highest revision number. If
+// several revisions share the highest revision number the one whose name sorts
+// last is returned. Latest returns nil if no revisions are supplied.
+func Latest(revs []PackageRevision) PackageRevision {
+	var latest PackageRevision
+	for _, r := range revs {
+		if latest == nil || revisionLess(latest, r) {
+			latest = r
+		}
+	}
+	return latest
+}
+
+func revisionLess(a, b PackageRevision) bool {
+	if a.GetRevision() != b.GetRevision() {
+		return a.GetRevision() < b.GetRevision()
+	}
+	return a.GetName() < b.GetName()
+}
+
+// NextRevision returns the revision number that should be used for a new
+// revision of a package, given its existing revisions. This is one greater than
+// the highest existing revision number, or 1 if there are no existing
+// revisions. NextRevision does not guard against two callers racing to create
+// revisions with the same number; callers should rely on optimistic
+// concurrency (e.g. creating revisions with deterministic names) to do so.
+func NextRevision(revs []PackageRevision) int64 {
+	max := int64(0)
+	for _, r := range revs {
+		if r.GetRevision() > max {
+			max = r.GetRevision()
+		}
+	}
+	return max + 1
+}
+
+// Validate returns an error for each invalid field of this spec. Revision
+// numbers must be at least 1, because revisions are 1-indexed and are ordered
+// by revision number for garbage collection.
+func (s PackageRevisionSpec) Validate() field.ErrorList {
+	errs := field.ErrorList{}
+	if s.Revision < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "revision"), s.Revision, errRevisionNotPositive))
+	}
+	return errs
+}
+
+// IsActive returns true if the desired state of this revision is active. Any
+// desired state other than PackageRevisionActive, including an unrecognised
+// one, is treated as inactive.
+func (s PackageRevisionSpec) IsActive() bool {
+	return s.DesiredState == PackageRevisionActive
+}
+
+// SetActive sets the desired state of this revision to PackageRevisionActive
+// if active is true, or to PackageRevisionInactive otherwise.
+func (s *PackageRevisionSpec) SetActive(active bool) {
+	s.DesiredState = PackageRevisionInactive
+	if active {
+		s.DesiredState = PackageRevisionActive
+	}
+}
+
+// MergeFrom merges the supplied observed status into this status. Conditions
+// set on this status replace observed conditions of the same type, while
+// observed conditions of other types are retained. The observed controller
+// and object references are retained unless they are set on this status. The
+// supplied status is not modified.
+func (s *PackageRevisionStatus) MergeFrom(observed PackageRevisionStatus) {
+	desired := s.Conditions
+	s.Conditions = nil
+	if observed.Conditions != nil {
+		s.Conditions = make([]runtimev1alpha1.Condition, len(observed.Conditions))
+		copy(s.Conditions, observed.Conditions)
+	}
+	s.SetConditions(desired...)
+
+	if s.ControllerRef.Name == "" {
+		s.ControllerRef = observed.ControllerRef
+	}
+
+	if s.ObjectRefs == nil && observed.ObjectRefs != nil {
+		s.ObjectRefs = make([]runtimev1alpha1.TypedReference, len(observed.ObjectRefs))
+		copy(s.ObjectRefs, observed.ObjectRefs)
+	}
+}
+
+// IndexObjectRefs indexes the supplied object references by their
+// GroupVersionKind and name. Later references replace earlier references to an
+// object of the same GroupVersionKind and name.
+func IndexObjectRefs(refs []runtimev1alpha1.TypedReference) map[schema.GroupVersionKind]map[string]runtimev1alpha1.TypedReference {
+	idx := make(map[schema.GroupVersionKind]map[string]runtimev1alpha1.TypedReference)
+	for _, ref := range refs {
+		gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+		if idx[gvk] == nil {
+			idx[gvk] = make(map[string]runtimev1alpha1.TypedReference)
+		}
+		idx[gvk][ref.Name] = ref
+	}
+	return idx
+}
+
+// Contains returns true if the supplied object references include a reference
+// to the named object of the supplied GroupVersionKind.
+func Contains(refs []runtimev1alpha1.TypedReference, gvk schema.GroupVersionKind, name string) bool {
+	_, ok := IndexObjectRefs(refs)[gvk][name]
+	return ok
+}
+
+// EffectivePullPolicy returns the pull policy that should be used to pull the
+// package image of this revision. The revision's package pull policy is used
+// if it is set; otherwise the image is pulled only if it is not present, which
+// is the default package pull policy.
+func (s PackageRevisionSpec) EffectivePullPolicy() corev1.PullPolicy {
+	if s.PackagePullPolicy != nil && *s.PackagePullPolicy != "" {
+		return *s.PackagePullPolicy
+	}
+	return corev1.PullIfNotPresent
+}
+
+// PullSecrets returns the package pull secrets of this revision followed by
+// any of the supplied default pull secrets that the revision does not already
+// specify. Each secret appears at most once.
+func (s PackageRevisionSpec) PullSecrets(defaults ...corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	seen := make(map[string]bool, len(s.PackagePullSecrets)+len(defaults))
+	secrets := make([]corev1.LocalObjectReference, 0, len(s.PackagePullSecrets)+len(defaults))
+	for _, refs := range [][]corev1.LocalObjectReference{s.PackagePullSecrets, defaults} {
+		for _, ref := range refs {
+			if seen[ref.Name] {
+				continue
+			}
+			seen[ref.Name] = true
+			secrets = append(secrets, ref)
+		}
+	}
+	return secrets
+}
+
+// DependencySummary returns a human readable summary of the packages this
+// revision depends on, with one line per dependency in the form
+// '- <package> <version>', sorted by package. An empty string is returned if
+// the revision has no dependencies.
+func (s PackageRevisionStatus) DependencySummary() string {
+	lines := make([]string, 0, len(s.DependsOn))
+	for _, d := range s.DependsOn {
+		pkg := ""
+		switch {
+		case d.Provider != nil:
+			pkg = *d.Provider
+		case d.Configuration != nil:
+			pkg = *d.Configuration
+		}
+		lines = append(lines, strings.TrimSpace("- "+pkg+" "+d.Version))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// IsHealthy returns true if the revision is both installed and healthy, i.e.
+// if its Installed and Healthy conditions are both True. A revision that is
+// missing either condition is not healthy.
+func (s PackageRevisionStatus) IsHealthy() bool {
+	return s.GetCondition(TypeInstalled).Status == corev1.ConditionTrue &&
+		s.GetCondition(TypeHealthy).Status == corev1.ConditionTrue
+}
@@ -0,0 +1,609 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+
+	pkgmeta "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+)
+
+func revision(name string, rev int64) PackageRevision {
+	return &ProviderRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       PackageRevisionSpec{Revision: rev},
+	}
+}
+
+func names(revs []PackageRevision) []string {
+	n := make([]string, len(revs))
+	for i, r := range revs {
+		n[i] = r.GetName()
+	}
+	return n
+}
+
+func TestSortByRevision(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		revs   []PackageRevision
+		want   []string
+	}{
+		"Empty": {
+			reason: "Sorting no revisions should be a no-op.",
+			revs:   []PackageRevision{},
+			want:   []string{},
+		},
+		"OutOfOrder": {
+			reason: "Revisions should be sorted in ascending order of revision number.",
+			revs:   []PackageRevision{revision("c", 3), revision("a", 1), revision("b", 2)},
+			want:   []string{"a", "b", "c"},
+		},
+		"DuplicateRevisions": {
+			reason: "Revisions with equal revision numbers should be sorted by name.",
+			revs:   []PackageRevision{revision("z", 2), revision("y", 2), revision("x", 1)},
+			want:   []string{"x", "y", "z"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			SortByRevision(tc.revs)
+			if diff := cmp.Diff(tc.want, names(tc.revs)); diff != "" {
+				t.Errorf("\n%s\nSortByRevision(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		revs   []PackageRevision
+		want   string
+	}{
+		"Empty": {
+			reason: "There is no latest revision of no revisions.",
+			revs:   []PackageRevision{},
+		},
+		"OutOfOrder": {
+			reason: "The revision with the highest revision number should be returned.",
+			revs:   []PackageRevision{revision("b", 2), revision("c", 3), revision("a", 1)},
+			want:   "c",
+		},
+		"DuplicateRevisions": {
+			reason: "The last named of the revisions with the highest revision number should be returned.",
+			revs:   []PackageRevision{revision("y", 2), revision("z", 2), revision("x", 1)},
+			want:   "z",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ""
+			if l := Latest(tc.revs); l != nil {
+				got = l.GetName()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nLatest(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNextRevision(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		revs   []PackageRevision
+		want   int64
+	}{
+		"Empty": {
+			reason: "The first revision of a package should be revision 1.",
+			revs:   []PackageRevision{},
+			want:   1,
+		},
+		"Contiguous": {
+			reason: "The next revision should be one greater than the highest existing revision.",
+			revs:   []PackageRevision{revision("a", 1), revision("c", 3), revision("b", 2)},
+			want:   4,
+		},
+		"Gapped": {
+			reason: "Gaps in revision numbers, e.g. due to garbage collection, should not be filled.",
+			revs:   []PackageRevision{revision("d", 4), revision("g", 7)},
+			want:   8,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NextRevision(tc.revs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNextRevision(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParent(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		rev    PackageRevision
+	}{
+		"ProviderRevision": {
+			reason: "The parent of a ProviderRevision should round-trip via its labels.",
+			rev:    &ProviderRevision{},
+		},
+		"ConfigurationRevision": {
+			reason: "The parent of a ConfigurationRevision should round-trip via its labels.",
+			rev: &ConfigurationRevision{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"cool": "very"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.rev.SetParent("coolpkg")
+
+			if diff := cmp.Diff("coolpkg", tc.rev.GetParent()); diff != "" {
+				t.Errorf("\n%s\nGetParent(): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			sel := labels.SelectorFromSet(labels.Set{LabelParentPackage: "coolpkg"})
+			if !sel.Matches(labels.Set(tc.rev.GetLabels())) {
+				t.Errorf("\n%s\nSetParent(...): selector %q does not match labels %v", tc.reason, sel, tc.rev.GetLabels())
+			}
+		})
+	}
+}
+
+func TestMergeFrom(t *testing.T) {
+	refs := []runtimev1alpha1.TypedReference{{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool"}}
+	otherRefs := []runtimev1alpha1.TypedReference{{APIVersion: "example.org/v1", Kind: "Cool", Name: "cooler"}}
+
+	cases := map[string]struct {
+		reason   string
+		s        PackageRevisionStatus
+		observed PackageRevisionStatus
+		want     PackageRevisionStatus
+	}{
+		"ConditionsUpdatedObjectRefsRetained": {
+			reason: "Desired conditions should replace observed conditions of the same type, and observed object references should be retained.",
+			s: PackageRevisionStatus{
+				ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Healthy()}},
+			},
+			observed: PackageRevisionStatus{
+				ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Active(), Unhealthy()}},
+				ControllerRef:     runtimev1alpha1.Reference{Name: "cool-controller"},
+				ObjectRefs:        refs,
+			},
+			want: PackageRevisionStatus{
+				ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Active(), Healthy()}},
+				ControllerRef:     runtimev1alpha1.Reference{Name: "cool-controller"},
+				ObjectRefs:        refs,
+			},
+		},
+		"ObjectRefsChanged": {
+			reason: "Object references set on the desired status should replace the observed object references.",
+			s: PackageRevisionStatus{
+				ObjectRefs: otherRefs,
+			},
+			observed: PackageRevisionStatus{
+				ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Active()}},
+				ObjectRefs:        refs,
+			},
+			want: PackageRevisionStatus{
+				ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Active()}},
+				ObjectRefs:        otherRefs,
+			},
+		},
+		"ObjectRefsCleared": {
+			reason: "An explicitly empty list of object references should replace the observed object references.",
+			s: PackageRevisionStatus{
+				ObjectRefs: []runtimev1alpha1.TypedReference{},
+			},
+			observed: PackageRevisionStatus{
+				ObjectRefs: refs,
+			},
+			want: PackageRevisionStatus{
+				ObjectRefs: []runtimev1alpha1.TypedReference{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.s.MergeFrom(tc.observed)
+			if diff := cmp.Diff(tc.want, tc.s, cmpopts.IgnoreFields(runtimev1alpha1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\nMergeFrom(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergeFromDoesNotAlias(t *testing.T) {
+	observed := PackageRevisionStatus{
+		ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Unhealthy()}},
+		ObjectRefs:        []runtimev1alpha1.TypedReference{{Name: "cool"}},
+	}
+
+	s := PackageRevisionStatus{ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Healthy()}}}
+	s.MergeFrom(observed)
+	s.ObjectRefs[0].Name = "cooler"
+
+	want := PackageRevisionStatus{
+		ConditionedStatus: runtimev1alpha1.ConditionedStatus{Conditions: []runtimev1alpha1.Condition{Unhealthy()}},
+		ObjectRefs:        []runtimev1alpha1.TypedReference{{Name: "cool"}},
+	}
+	if diff := cmp.Diff(want, observed, cmpopts.IgnoreFields(runtimev1alpha1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("MergeFrom(...): observed status was modified: -want, +got:\n%s", diff)
+	}
+}
+
+func TestIndexObjectRefs(t *testing.T) {
+	cool := schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Cool"}
+	crd := schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+	refs := []runtimev1alpha1.TypedReference{
+		{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: "a"},
+		{APIVersion: "example.org/v1", Kind: "Cool", Name: "cooler"},
+		{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "cool"},
+		{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: "b"},
+	}
+
+	want := map[schema.GroupVersionKind]map[string]runtimev1alpha1.TypedReference{
+		cool: {
+			"cool":   {APIVersion: "example.org/v1", Kind: "Cool", Name: "cool", UID: "b"},
+			"cooler": {APIVersion: "example.org/v1", Kind: "Cool", Name: "cooler"},
+		},
+		crd: {
+			"cool": {APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "cool"},
+		},
+	}
+
+	if diff := cmp.Diff(want, IndexObjectRefs(refs)); diff != "" {
+		t.Errorf("IndexObjectRefs(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestContains(t *testing.T) {
+	refs := []runtimev1alpha1.TypedReference{
+		{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool"},
+		{APIVersion: "example.org/v1", Kind: "Cool", Name: "cool"},
+	}
+
+	cases := map[string]struct {
+		reason string
+		gvk    schema.GroupVersionKind
+		name   string
+		want   bool
+	}{
+		"Hit": {
+			reason: "A referenced object should be contained.",
+			gvk:    schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Cool"},
+			name:   "cool",
+			want:   true,
+		},
+		"NameMiss": {
+			reason: "An unreferenced object of a referenced kind should not be contained.",
+			gvk:    schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Cool"},
+			name:   "cooler",
+			want:   false,
+		},
+		"VersionMiss": {
+			reason: "A referenced object name at a different version should not be contained.",
+			gvk:    schema.GroupVersionKind{Group: "example.org", Version: "v2", Kind: "Cool"},
+			name:   "cool",
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Contains(refs, tc.gvk, tc.name)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nContains(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEffectivePullPolicy(t *testing.T) {
+	never := corev1.PullNever
+	empty := corev1.PullPolicy("")
+
+	cases := map[string]struct {
+		reason string
+		spec   PackageRevisionSpec
+		want   corev1.PullPolicy
+	}{
+		"Explicit": {
+			reason: "An explicit pull policy should always be used.",
+			spec:   PackageRevisionSpec{Package: "acme/provider-cool:latest", PackagePullPolicy: &never},
+			want:   corev1.PullNever,
+		},
+		"Unset": {
+			reason: "Images should be pulled if not present when no pull policy is set.",
+			spec:   PackageRevisionSpec{Package: "acme/provider-cool:latest"},
+			want:   corev1.PullIfNotPresent,
+		},
+		"Empty": {
+			reason: "Images should be pulled if not present when the pull policy is empty.",
+			spec:   PackageRevisionSpec{Package: "acme/provider-cool:latest", PackagePullPolicy: &empty},
+			want:   corev1.PullIfNotPresent,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.spec.EffectivePullPolicy()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nEffectivePullPolicy(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPullSecrets(t *testing.T) {
+	cool := corev1.LocalObjectReference{Name: "cool"}
+	cooler := corev1.LocalObjectReference{Name: "cooler"}
+	coolest := corev1.LocalObjectReference{Name: "coolest"}
+
+	cases := map[string]struct {
+		reason   string
+		spec     PackageRevisionSpec
+		defaults []corev1.LocalObjectReference
+		want     []corev1.LocalObjectReference
+	}{
+		"NoSecrets": {
+			reason: "No secrets should be returned if neither the revision nor the defaults specify any.",
+			spec:   PackageRevisionSpec{},
+			want:   []corev1.LocalObjectReference{},
+		},
+		"RevisionOnly": {
+			reason: "The revision's secrets should be carried when there are no defaults.",
+			spec:   PackageRevisionSpec{PackagePullSecrets: []corev1.LocalObjectReference{cool, cooler}},
+			want:   []corev1.LocalObjectReference{cool, cooler},
+		},
+		"Merged": {
+			reason:   "Default secrets should follow the revision's secrets.",
+			spec:     PackageRevisionSpec{PackagePullSecrets: []corev1.LocalObjectReference{cool}},
+			defaults: []corev1.LocalObjectReference{coolest},
+			want:     []corev1.LocalObjectReference{cool, coolest},
+		},
+		"Deduplicated": {
+			reason:   "Each secret should be returned only once.",
+			spec:     PackageRevisionSpec{PackagePullSecrets: []corev1.LocalObjectReference{cool, cooler, cool}},
+			defaults: []corev1.LocalObjectReference{cooler, coolest, coolest},
+			want:     []corev1.LocalObjectReference{cool, cooler, coolest},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.spec.PullSecrets(tc.defaults...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPullSecrets(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDependencySummary(t *testing.T) {
+	aws := "acme/provider-aws"
+	gcp := "acme/provider-gcp"
+	platform := "acme/platform"
+
+	cases := map[string]struct {
+		reason string
+		status PackageRevisionStatus
+		want   string
+	}{
+		"NoDependencies": {
+			reason: "An empty summary should be returned when there are no dependencies.",
+			status: PackageRevisionStatus{},
+			want:   "",
+		},
+		"MultipleDependencies": {
+			reason: "Each dependency should be summarized on its own line, sorted by package.",
+			status: PackageRevisionStatus{
+				DependsOn: []pkgmeta.Dependency{
+					{Provider: &gcp, Version: ">=0.2.0"},
+					{Configuration: &platform, Version: "v1.0.0"},
+					{Provider: &aws, Version: ">=0.4.0"},
+				},
+			},
+			want: "- acme/platform v1.0.0\n- acme/provider-aws >=0.4.0\n- acme/provider-gcp >=0.2.0",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.status.DependencySummary()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDependencySummary(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsHealthy(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		conditions []runtimev1alpha1.Condition
+		want       bool
+	}{
+		"NoConditions": {
+			reason: "A revision without conditions is not healthy.",
+			want:   false,
+		},
+		"MissingHealthy": {
+			reason:     "A revision without a Healthy condition is not healthy.",
+			conditions: []runtimev1alpha1.Condition{Active()},
+			want:       false,
+		},
+		"MissingInstalled": {
+			reason:     "A revision without an Installed condition is not healthy.",
+			conditions: []runtimev1alpha1.Condition{Healthy()},
+			want:       false,
+		},
+		"Unhealthy": {
+			reason:     "An installed revision that is unhealthy is not healthy.",
+			conditions: []runtimev1alpha1.Condition{Active(), Unhealthy()},
+			want:       false,
+		},
+		"NotInstalled": {
+			reason:     "A healthy revision that is not installed is not healthy.",
+			conditions: []runtimev1alpha1.Condition{Inactive(), Healthy()},
+			want:       false,
+		},
+		"HealthyAndInstalled": {
+			reason:     "An installed revision that is healthy is healthy.",
+			conditions: []runtimev1alpha1.Condition{Active(), Healthy()},
+			want:       true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := PackageRevisionStatus{}
+			s.SetConditions(tc.conditions...)
+			got := s.IsHealthy()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsHealthy(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   PackageRevisionSpec
+		want   field.ErrorList
+	}{
+		"Zero": {
+			reason: "A revision number of zero should be invalid.",
+			spec:   PackageRevisionSpec{Revision: 0},
+			want:   field.ErrorList{field.Invalid(field.NewPath("spec", "revision"), int64(0), errRevisionNotPositive)},
+		},
+		"Negative": {
+			reason: "A negative revision number should be invalid.",
+			spec:   PackageRevisionSpec{Revision: -1},
+			want:   field.ErrorList{field.Invalid(field.NewPath("spec", "revision"), int64(-1), errRevisionNotPositive)},
+		},
+		"First": {
+			reason: "A revision number of one should be valid.",
+			spec:   PackageRevisionSpec{Revision: 1},
+			want:   field.ErrorList{},
+		},
+		"Positive": {
+			reason: "A revision number greater than one should be valid.",
+			spec:   PackageRevisionSpec{Revision: 42},
+			want:   field.ErrorList{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.spec.Validate()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidate(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   PackageRevisionSpec
+		want   bool
+	}{
+		"Active": {
+			reason: "A revision whose desired state is active should be active.",
+			spec:   PackageRevisionSpec{DesiredState: PackageRevisionActive},
+			want:   true,
+		},
+		"Inactive": {
+			reason: "A revision whose desired state is inactive should not be active.",
+			spec:   PackageRevisionSpec{DesiredState: PackageRevisionInactive},
+			want:   false,
+		},
+		"Unset": {
+			reason: "A revision without a desired state should not be active.",
+			spec:   PackageRevisionSpec{},
+			want:   false,
+		},
+		"Unrecognised": {
+			reason: "A revision with an unrecognised desired state should not be active.",
+			spec:   PackageRevisionSpec{DesiredState: PackageRevisionDesiredState("Dormant")},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.spec.IsActive()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsActive(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetActive(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		active bool
+		want   PackageRevisionDesiredState
+	}{
+		"Active": {
+			reason: "Setting a revision active should set its desired state to active.",
+			active: true,
+			want:   PackageRevisionActive,
+		},
+		"Inactive": {
+			reason: "Setting a revision inactive should set its desired state to inactive.",
+			active: false,
+			want:   PackageRevisionInactive,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := PackageRevisionSpec{DesiredState: PackageRevisionDesiredState("Dormant")}
+			s.SetActive(tc.active)
+			if diff := cmp.Diff(tc.want, s.DesiredState); diff != "" {
+				t.Errorf("\n%s\nSetActive(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.active, s.IsActive()); diff != "" {
+				t.Errorf("\n%s\nIsActive(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
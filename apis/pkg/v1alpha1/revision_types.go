@@ -21,8 +21,14 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
 	runtimev1alpha1 "github.com/crossplane/crossplane-runtime/apis/core/v1alpha1"
+
+	pkgmeta "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
 )
 
+// LabelParentPackage is the key of the label that associates a package revision
+// with its parent package. Its value is the name of the parent package.
+const LabelParentPackage = "pkg.crossplane.io/package"
+
 // PackageRevisionDesiredState is the desired state of the package revision.
 type PackageRevisionDesiredState string
 
@@ -62,7 +68,9 @@ type PackageRevisionSpec struct {
 	PackagePullPolicy *corev1.PullPolicy `json:"packagePullPolicy,omitempty"`
 
 	// Revision number. Indicates when the revision will be garbage collected
-	// based on the parent's RevisionHistoryLimit.
+	// based on the parent's RevisionHistoryLimit. Revisions are 1-indexed; the
+	// first revision of a package is revision 1.
+	// +kubebuilder:validation:Minimum=1
 	Revision int64 `json:"revision"`
 
 	// IgnoreCrossplaneConstraints indicates to the package manager whether to
@@ -80,4 +88,9 @@ type PackageRevisionStatus struct {
 
 	// References to objects owned by PackageRevision.
 	ObjectRefs []runtimev1alpha1.TypedReference `json:"objectRefs,omitempty"`
+
+	// DependsOn is the list of packages that this package revision depends
+	// on, as declared by the package's metadata.
+	// +optional
+	DependsOn []pkgmeta.Dependency `json:"dependsOn,omitempty"`
 }
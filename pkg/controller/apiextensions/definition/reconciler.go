@@ -187,7 +187,9 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 		},
 
 		composite: definition{
-			CRDRenderer:      CRDRenderFn(ccrd.ForCompositeResource),
+			CRDRenderer: CRDRenderFn(func(d *v1alpha1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+				return ccrd.ForCompositeResource(d)
+			}),
 			ControllerEngine: controller.NewEngine(mgr),
 			Finalizer:        resource.NewAPIFinalizer(kube, finalizer),
 		},
@@ -186,7 +186,9 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 		},
 
 		claim: definition{
-			CRDRenderer:      CRDRenderFn(ccrd.ForCompositeResourceClaim),
+			CRDRenderer: CRDRenderFn(func(d *v1alpha1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+				return ccrd.ForCompositeResourceClaim(d)
+			}),
 			ControllerEngine: controller.NewEngine(mgr),
 			Finalizer:        resource.NewAPIFinalizer(kube, finalizer),
 		},
@@ -37,6 +37,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
+	pkgmeta "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
 	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
 	verfake "github.com/crossplane/crossplane/pkg/version/fake"
 	"github.com/crossplane/crossplane/pkg/xpkg"
@@ -118,10 +119,22 @@ spec:
   crossplane:
     version: ">v0.13.0"`)
 
+var providerWithDependenciesBytes = []byte(`apiVersion: meta.pkg.crossplane.io/v1alpha1
+kind: Provider
+metadata:
+  name: test
+spec:
+  controller:
+    image: crossplane/provider-test-controller:v0.0.1
+  dependsOn:
+  - provider: crossplane/provider-helm
+    version: ">=v0.2.0"`)
+
 func TestReconcile(t *testing.T) {
 	errBoom := errors.New("boom")
 	now := metav1.Now()
 	trueVal := true
+	helm := "crossplane/provider-helm"
 
 	metaScheme, _ := xpkg.BuildMetaScheme()
 	objScheme, _ := xpkg.BuildObjectScheme()
@@ -608,6 +621,51 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{RequeueAfter: longWait},
 			},
 		},
+		"SuccessfulActiveRevisionDependencies": {
+			reason: "An active revision should record the dependencies its package declares.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1alpha1.PackageRevision { return &v1alpha1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o runtime.Object) error {
+								pr := o.(*v1alpha1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1alpha1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1alpha1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o runtime.Object) error {
+								want := &v1alpha1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1alpha1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1alpha1.PackageRevisionActive)
+								want.SetDependencies([]pkgmeta.Dependency{{Provider: &helm, Version: ">=v0.2.0"}})
+								want.SetConditions(v1alpha1.Healthy())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockDelete: test.NewMockDeleteFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(NewNopHooks()),
+					WithEstablisher(NewMockEstablisher()),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerWithDependenciesBytes))),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: longWait},
+			},
+		},
 		"SuccessfulActiveRevisionIgnoreConstraints": {
 			reason: "An active revision with incompatible Crossplane version should install successfully when constraints ignored.",
 			args: args{
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+const (
+	errFmtParseConstraint = "cannot parse version constraint %q"
+)
+
+// constraintVersion matches the versions referenced by a semver constraint,
+// including wildcard versions such as 1.2.x.
+var constraintVersion = regexp.MustCompile(`v?[0-9xX*]+(\.[0-9xX*]+){0,2}(-[0-9A-Za-z.\-]+)?(\+[0-9A-Za-z.\-]+)?`)
+
+// IntersectConstraints computes the intersection of the supplied semver
+// constraints, e.g. those of two packages that depend on the same package. It
+// returns a constraint satisfied only by versions that satisfy both a and b,
+// and whether any version does so. The returned constraint is empty when no
+// version satisfies both constraints. An error is returned if either
+// constraint cannot be parsed.
+func IntersectConstraints(a, b string) (string, bool, error) {
+	if _, err := semver.NewConstraint(a); err != nil {
+		return "", false, errors.Wrapf(err, errFmtParseConstraint, a)
+	}
+	if _, err := semver.NewConstraint(b); err != nil {
+		return "", false, errors.Wrapf(err, errFmtParseConstraint, b)
+	}
+
+	// (a1 || a2), (b1 || b2) is equivalent to a1,b1 || a1,b2 || a2,b1 || a2,b2.
+	// We keep only the combinations that at least one version satisfies.
+	var groups []string
+	for _, ag := range strings.Split(a, "||") {
+		for _, bg := range strings.Split(b, "||") {
+			g := strings.TrimSpace(ag) + ", " + strings.TrimSpace(bg)
+			if satisfiable(g) {
+				groups = append(groups, g)
+			}
+		}
+	}
+
+	return strings.Join(groups, " || "), len(groups) > 0, nil
+}
+
+// satisfiable returns true if any version satisfies the supplied constraint,
+// which must be valid. The lowest version that satisfies a constraint is
+// either 0.0.0, a version referenced by the constraint, or the version that
+// immediately follows the major, minor, or patch version of a referenced
+// version, so it suffices to check each of those candidates.
+func satisfiable(constraint string) bool {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+
+	candidates := []semver.Version{*semver.MustParse("0.0.0")}
+	for _, s := range constraintVersion.FindAllString(constraint, -1) {
+		v, err := semver.NewVersion(strings.NewReplacer("x", "0", "X", "0", "*", "0").Replace(s))
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, *v, v.IncPatch(), v.IncMinor(), v.IncMajor())
+	}
+
+	for i := range candidates {
+		if c.Check(&candidates[i]) {
+			return true
+		}
+	}
+	return false
+}
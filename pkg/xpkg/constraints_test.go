@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIntersectConstraints(t *testing.T) {
+	type want struct {
+		constraint string
+		ok         bool
+		err        bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		a      string
+		b      string
+		want   want
+	}{
+		"Overlapping": {
+			reason: "Overlapping ranges should intersect.",
+			a:      ">=1.0.0, <2.0.0",
+			b:      ">=1.5.0",
+			want:   want{constraint: ">=1.0.0, <2.0.0, >=1.5.0", ok: true},
+		},
+		"Touching": {
+			reason: "Ranges that share a single inclusive bound should intersect.",
+			a:      "<=1.2.3",
+			b:      ">=1.2.3",
+			want:   want{constraint: "<=1.2.3, >=1.2.3", ok: true},
+		},
+		"TildeAndCaret": {
+			reason: "Tilde and caret ranges should intersect where they overlap.",
+			a:      "~1.2.0",
+			b:      "^1.2.5",
+			want:   want{constraint: "~1.2.0, ^1.2.5", ok: true},
+		},
+		"Wildcard": {
+			reason: "Wildcard ranges should intersect with the ranges they contain.",
+			a:      "1.x",
+			b:      ">1.9.9",
+			want:   want{constraint: "1.x, >1.9.9", ok: true},
+		},
+		"HyphenRange": {
+			reason: "Hyphen ranges should intersect where they overlap.",
+			a:      "1.2 - 1.4",
+			b:      ">=1.4.0",
+			want:   want{constraint: "1.2 - 1.4, >=1.4.0", ok: true},
+		},
+		"Disjoint": {
+			reason: "Disjoint ranges should not intersect.",
+			a:      "<1.0.0",
+			b:      ">=1.0.0",
+			want:   want{ok: false},
+		},
+		"DisjointExclusiveBounds": {
+			reason: "Ranges that share only an exclusive bound should not intersect.",
+			a:      "<1.2.3",
+			b:      ">=1.2.3",
+			want:   want{ok: false},
+		},
+		"Excluded": {
+			reason: "A single version should not intersect with a range that excludes it.",
+			a:      "1.2.3",
+			b:      "!=1.2.3",
+			want:   want{ok: false},
+		},
+		"ExcludedWildcard": {
+			reason: "A range should not intersect with a wildcard that excludes all of it.",
+			a:      "~1.2.0",
+			b:      "!=1.2.x",
+			want:   want{ok: false},
+		},
+		"PartiallyExcluded": {
+			reason: "A range should intersect with a wildcard that excludes only part of it.",
+			a:      "^1.2.0",
+			b:      "!=1.2.x",
+			want:   want{constraint: "^1.2.0, !=1.2.x", ok: true},
+		},
+		"Alternatives": {
+			reason: "Only the alternatives that intersect should be returned.",
+			a:      "^1.0.0 || ^2.0.0",
+			b:      ">=1.5.0, <1.6.0 || >=3.0.0",
+			want:   want{constraint: "^1.0.0, >=1.5.0, <1.6.0", ok: true},
+		},
+		"InvalidA": {
+			reason: "An error should be returned if the first constraint is invalid.",
+			a:      "one point oh",
+			b:      ">=1.0.0",
+			want:   want{err: true},
+		},
+		"InvalidB": {
+			reason: "An error should be returned if the second constraint is invalid.",
+			a:      ">=1.0.0",
+			b:      ">>1.0.0",
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, ok, err := IntersectConstraints(tc.a, tc.b)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nIntersectConstraints(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\nIntersectConstraints(...): -want ok, +got ok:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.constraint, c); diff != "" {
+				t.Errorf("\n%s\nIntersectConstraints(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	pkgmeta "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+)
+
+const (
+	errFmtInvalidDependency = "invalid dependency on package %q"
+	errFmtUnsatisfiable     = "cannot satisfy dependencies: %s"
+	errFmtConflictingPins   = "pinned versions conflict with dependencies: %s"
+)
+
+// ResolveAll chooses a version of each package the supplied dependencies
+// depend on from the supplied index, which maps package names to their
+// available versions. The highest available version that satisfies every
+// dependency on a package is chosen. Available versions that are not valid
+// semantic versions are ignored. An error listing every package for which no
+// version can be chosen is returned if any dependency cannot be satisfied.
+func ResolveAll(deps []pkgmeta.Dependency, index map[string][]string) (map[string]string, error) {
+	return ResolveAllPinned(deps, index, nil)
+}
+
+// ResolveAllPinned is like ResolveAll, except that the version of each package
+// in the supplied pins map, which maps package names to versions, is chosen
+// regardless of the versions available in the index. Pins for packages that
+// are not depended upon are ignored. An error listing every pin that does not
+// satisfy every dependency on its package is returned if any pin conflicts
+// with a dependency. A pin that is not a valid semantic version conflicts
+// with every dependency.
+func ResolveAllPinned(deps []pkgmeta.Dependency, index map[string][]string, pins map[string]string) (map[string]string, error) {
+	constraints, requested, err := parseConstraints(deps)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string, len(constraints))
+	var unsatisfiable, conflicting []string
+	for pkg, cs := range constraints {
+		if pin, ok := pins[pkg]; ok {
+			if !pinSatisfiesAll(pin, cs) {
+				conflicting = append(conflicting, fmt.Sprintf("%s pinned to %s (%s)", pkg, pin, strings.Join(requested[pkg], ", ")))
+			}
+			resolved[pkg] = pin
+			continue
+		}
+		v, ok := highestSatisfying(index[pkg], cs)
+		if !ok {
+			unsatisfiable = append(unsatisfiable, fmt.Sprintf("%s (%s)", pkg, strings.Join(requested[pkg], ", ")))
+			continue
+		}
+		resolved[pkg] = v
+	}
+
+	if len(conflicting) > 0 {
+		sort.Strings(conflicting)
+		return nil, errors.Errorf(errFmtConflictingPins, strings.Join(conflicting, "; "))
+	}
+	if len(unsatisfiable) > 0 {
+		sort.Strings(unsatisfiable)
+		return nil, errors.Errorf(errFmtUnsatisfiable, strings.Join(unsatisfiable, "; "))
+	}
+	return resolved, nil
+}
+
+// parseConstraints returns the parsed version constraints of the supplied
+// dependencies, and the constraints as they were requested, keyed by the name
+// of the package they depend on.
+func parseConstraints(deps []pkgmeta.Dependency) (map[string][]*semver.Constraints, map[string][]string, error) {
+	constraints := map[string][]*semver.Constraints{}
+	requested := map[string][]string{}
+	for _, d := range deps {
+		pkg := d.PackageName()
+		c, err := semver.NewConstraint(d.Version)
+		if err != nil {
+			return nil, nil, errors.Wrapf(errors.Wrapf(err, errFmtParseConstraint, d.Version), errFmtInvalidDependency, pkg)
+		}
+		constraints[pkg] = append(constraints[pkg], c)
+		requested[pkg] = append(requested[pkg], d.Version)
+	}
+	return constraints, requested, nil
+}
+
+// pinSatisfiesAll returns true if the supplied pinned version can be parsed and
+// satisfies all of the supplied constraints.
+func pinSatisfiesAll(pin string, cs []*semver.Constraints) bool {
+	v, err := semver.NewVersion(pin)
+	if err != nil {
+		return false
+	}
+	return satisfiesAll(v, cs)
+}
+
+// OutdatedDependencies returns the supplied dependencies on installed packages
+// whose installed version no longer satisfies their version constraints. The
+// installed map maps package names to their installed versions. Dependencies on
+// packages that are not installed are not outdated. A dependency whose version
+// constraint or installed version cannot be parsed is considered outdated,
+// because the installed version cannot be shown to satisfy it.
+func OutdatedDependencies(deps []pkgmeta.Dependency, installed map[string]string) []pkgmeta.Dependency {
+	var outdated []pkgmeta.Dependency
+	for _, d := range deps {
+		iv, ok := installed[d.PackageName()]
+		if !ok {
+			continue
+		}
+		if !satisfies(iv, d.Version) {
+			outdated = append(outdated, d)
+		}
+	}
+	return outdated
+}
+
+// UpgradableDependencies returns the supplied dependencies on installed
+// packages for which the supplied index, which maps package names to their
+// available versions, includes a version that is newer than the installed
+// version and satisfies the dependency's version constraints. Dependencies on
+// packages that are not installed, or whose installed version cannot be parsed,
+// are not upgradable.
+func UpgradableDependencies(deps []pkgmeta.Dependency, installed map[string]string, index map[string][]string) []pkgmeta.Dependency {
+	var upgradable []pkgmeta.Dependency
+	for _, d := range deps {
+		iv, ok := installed[d.PackageName()]
+		if !ok {
+			continue
+		}
+		current, err := semver.NewVersion(iv)
+		if err != nil {
+			continue
+		}
+		c, err := semver.NewConstraint(d.Version)
+		if err != nil {
+			continue
+		}
+		if v, ok := highestSatisfying(index[d.PackageName()], []*semver.Constraints{c}); ok && semver.MustParse(v).GreaterThan(current) {
+			upgradable = append(upgradable, d)
+		}
+	}
+	return upgradable
+}
+
+// satisfies returns true if the supplied version satisfies the supplied
+// constraint, and both can be parsed.
+func satisfies(version, constraint string) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// highestSatisfying returns the highest of the supplied versions that
+// satisfies all of the supplied constraints, and whether one does.
+func highestSatisfying(versions []string, cs []*semver.Constraints) (string, bool) {
+	var highest *semver.Version
+	chosen := ""
+	for _, s := range versions {
+		v, err := semver.NewVersion(s)
+		if err != nil || !satisfiesAll(v, cs) {
+			continue
+		}
+		if highest == nil || v.GreaterThan(highest) {
+			highest, chosen = v, s
+		}
+	}
+	return chosen, highest != nil
+}
+
+func satisfiesAll(v *semver.Version, cs []*semver.Constraints) bool {
+	for _, c := range cs {
+		if !c.Check(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	pkgmeta "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+)
+
+func TestResolveAll(t *testing.T) {
+	aws := "crossplane/provider-aws"
+	gcp := "crossplane/provider-gcp"
+	azure := "crossplane/provider-azure"
+	platform := "crossplane/platform"
+
+	index := map[string][]string{
+		aws:      {"v0.1.0", "v0.2.0", "v0.3.0", "not-a-version"},
+		gcp:      {"v0.1.0", "v0.4.0"},
+		platform: {"v1.0.0"},
+	}
+
+	type want struct {
+		resolved map[string]string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		deps   []pkgmeta.Dependency
+		want   want
+	}{
+		"NoDependencies": {
+			reason: "No versions should be resolved when there are no dependencies.",
+			want:   want{resolved: map[string]string{}},
+		},
+		"FullyResolvable": {
+			reason: "The highest version satisfying every dependency on each package should be chosen.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &aws, Version: ">=v0.1.0"},
+				{Provider: &aws, Version: "<v0.3.0"},
+				{Provider: &gcp, Version: ">=v0.1.0"},
+				{Configuration: &platform, Version: "v1.0.0"},
+			},
+			want: want{resolved: map[string]string{
+				aws:      "v0.2.0",
+				gcp:      "v0.4.0",
+				platform: "v1.0.0",
+			}},
+		},
+		"PartiallyUnresolvable": {
+			reason: "Every package for which no available version satisfies its dependencies should be listed.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &aws, Version: ">=v0.1.0"},
+				{Provider: &gcp, Version: ">=v1.0.0"},
+				{Configuration: &platform, Version: ">=v2.0.0"},
+			},
+			want: want{err: errors.Errorf(errFmtUnsatisfiable, "crossplane/platform (>=v2.0.0); crossplane/provider-gcp (>=v1.0.0)")},
+		},
+		"ConflictingVersions": {
+			reason: "A package should be unresolvable when its dependencies require disjoint versions.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &aws, Version: "<v0.2.0"},
+				{Provider: &aws, Version: ">=v0.3.0"},
+			},
+			want: want{err: errors.Errorf(errFmtUnsatisfiable, "crossplane/provider-aws (<v0.2.0, >=v0.3.0)")},
+		},
+		"NotInIndex": {
+			reason: "A package that is not in the index should be unresolvable.",
+			deps: []pkgmeta.Dependency{
+				{Configuration: &platform, Version: ">=v0.1.0"},
+				{Provider: &azure, Version: ">=v0.1.0"},
+			},
+			want: want{err: errors.Errorf(errFmtUnsatisfiable, "crossplane/provider-azure (>=v0.1.0)")},
+		},
+		"InvalidConstraint": {
+			reason: "An error should be returned if a dependency's version constraint cannot be parsed.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &aws, Version: "latest"},
+			},
+			want: want{err: errors.Wrapf(errors.Wrapf(errors.New("improper constraint: latest"), errFmtParseConstraint, "latest"), errFmtInvalidDependency, aws)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolveAll(tc.deps, index)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveAll(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resolved, got); diff != "" {
+				t.Errorf("\n%s\nResolveAll(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResolveAllPinned(t *testing.T) {
+	aws := "crossplane/provider-aws"
+	gcp := "crossplane/provider-gcp"
+
+	index := map[string][]string{
+		aws: {"v0.1.0", "v0.2.0", "v0.3.0"},
+		gcp: {"v0.1.0", "v0.4.0"},
+	}
+
+	type want struct {
+		resolved map[string]string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		deps   []pkgmeta.Dependency
+		pins   map[string]string
+		want   want
+	}{
+		"CompatiblePin": {
+			reason: "A pinned version that satisfies every dependency should be chosen, even if it is not in the index.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &aws, Version: ">=v0.1.0"},
+				{Provider: &aws, Version: "<v0.3.0"},
+				{Provider: &gcp, Version: ">=v0.1.0"},
+			},
+			pins: map[string]string{aws: "v0.2.1"},
+			want: want{resolved: map[string]string{
+				aws: "v0.2.1",
+				gcp: "v0.4.0",
+			}},
+		},
+		"UnusedPin": {
+			reason: "A pin for a package that is not depended upon should be ignored.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &gcp, Version: ">=v0.1.0"},
+			},
+			pins: map[string]string{aws: "v0.2.1"},
+			want: want{resolved: map[string]string{
+				gcp: "v0.4.0",
+			}},
+		},
+		"ConflictingPin": {
+			reason: "An error should be returned if a pinned version does not satisfy a dependency.",
+			deps: []pkgmeta.Dependency{
+				{Provider: &aws, Version: ">=v0.1.0"},
+				{Provider: &aws, Version: "<v0.3.0"},
+				{Provider: &gcp, Version: ">=v0.1.0"},
+			},
+			pins: map[string]string{aws: "v0.3.0", gcp: "not-a-version"},
+			want: want{err: errors.Errorf(errFmtConflictingPins, "crossplane/provider-aws pinned to v0.3.0 (>=v0.1.0, <v0.3.0); crossplane/provider-gcp pinned to not-a-version (>=v0.1.0)")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolveAllPinned(tc.deps, index, tc.pins)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveAllPinned(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resolved, got); diff != "" {
+				t.Errorf("\n%s\nResolveAllPinned(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOutdatedDependencies(t *testing.T) {
+	aws := "crossplane/provider-aws"
+	gcp := "crossplane/provider-gcp"
+	azure := "crossplane/provider-azure"
+
+	installed := map[string]string{
+		aws: "v0.2.0",
+		gcp: "v0.1.0",
+	}
+
+	cases := map[string]struct {
+		reason string
+		deps   []pkgmeta.Dependency
+		want   []pkgmeta.Dependency
+	}{
+		"Satisfied": {
+			reason: "Dependencies whose installed version satisfies their constraints should not be outdated.",
+			deps:   []pkgmeta.Dependency{{Provider: &aws, Version: ">=v0.1.0"}, {Provider: &gcp, Version: "v0.1.0"}},
+		},
+		"Unsatisfied": {
+			reason: "Dependencies whose installed version does not satisfy their constraints should be outdated.",
+			deps:   []pkgmeta.Dependency{{Provider: &aws, Version: ">=v0.3.0"}, {Provider: &gcp, Version: "v0.1.0"}},
+			want:   []pkgmeta.Dependency{{Provider: &aws, Version: ">=v0.3.0"}},
+		},
+		"InvalidConstraint": {
+			reason: "Dependencies whose constraints cannot be parsed should be outdated.",
+			deps:   []pkgmeta.Dependency{{Provider: &aws, Version: "latest"}},
+			want:   []pkgmeta.Dependency{{Provider: &aws, Version: "latest"}},
+		},
+		"NotInstalled": {
+			reason: "Dependencies on packages that are not installed should not be outdated.",
+			deps:   []pkgmeta.Dependency{{Provider: &azure, Version: ">=v0.1.0"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := OutdatedDependencies(tc.deps, installed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nOutdatedDependencies(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpgradableDependencies(t *testing.T) {
+	aws := "crossplane/provider-aws"
+	gcp := "crossplane/provider-gcp"
+	azure := "crossplane/provider-azure"
+
+	installed := map[string]string{
+		aws: "v0.2.0",
+		gcp: "v0.4.0",
+	}
+	index := map[string][]string{
+		aws:   {"v0.1.0", "v0.2.0", "v0.3.0", "v1.0.0"},
+		gcp:   {"v0.1.0", "v0.4.0"},
+		azure: {"v0.1.0"},
+	}
+
+	cases := map[string]struct {
+		reason string
+		deps   []pkgmeta.Dependency
+		want   []pkgmeta.Dependency
+	}{
+		"NewerAvailable": {
+			reason: "Dependencies for which a newer version satisfying their constraints is available should be upgradable.",
+			deps:   []pkgmeta.Dependency{{Provider: &aws, Version: "<v1.0.0"}},
+			want:   []pkgmeta.Dependency{{Provider: &aws, Version: "<v1.0.0"}},
+		},
+		"NewerOutOfRange": {
+			reason: "Dependencies for which only newer versions outside their constraints are available should not be upgradable.",
+			deps:   []pkgmeta.Dependency{{Provider: &aws, Version: "<v0.3.0"}},
+		},
+		"Latest": {
+			reason: "Dependencies whose installed version is the newest available should not be upgradable.",
+			deps:   []pkgmeta.Dependency{{Provider: &gcp, Version: ">=v0.1.0"}},
+		},
+		"NotInstalled": {
+			reason: "Dependencies on packages that are not installed should not be upgradable.",
+			deps:   []pkgmeta.Dependency{{Provider: &azure, Version: ">=v0.1.0"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := UpgradableDependencies(tc.deps, installed, index)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nUpgradableDependencies(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
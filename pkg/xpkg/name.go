@@ -21,6 +21,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 	"sigs.k8s.io/yaml"
 )
@@ -43,6 +45,8 @@ const (
 	XpkgMatchPattern string = "*" + XpkgExtension
 )
 
+const errFmtParseImage = "cannot parse package image %q"
+
 func truncate(str string, num int) string {
 	t := str
 	if len(str) > num {
@@ -59,6 +63,21 @@ func FriendlyID(name, hash string) string {
 	return id
 }
 
+// CanonicalImage returns the canonical form of the supplied package image
+// reference, such that equivalent references (e.g. acme/foo:v1 and
+// docker.io/acme/foo:v1) have the same canonical form. The canonical form
+// always includes a registry host, and either a tag or a digest. The implicit
+// Docker Hub registry and latest tag are made explicit. A tag is omitted from
+// references that include both a tag and a digest, because the digest alone
+// identifies the image.
+func CanonicalImage(ref string) (string, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, errFmtParseImage, ref)
+	}
+	return r.Name(), nil
+}
+
 // BuildPath builds a path for a compiled Crossplane package. If file name has
 // extension it will be replaced.
 func BuildPath(path, name string) string {
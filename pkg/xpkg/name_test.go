@@ -86,6 +86,69 @@ func TestFriendlyID(t *testing.T) {
 	}
 }
 
+func TestCanonicalImage(t *testing.T) {
+	digest := "sha256:ecd67c59ab6f1d1ac597d5a6e41bae2b7e0c6c3c9d226f4c21e8a857b74c1e3f"
+
+	type want struct {
+		image string
+		err   bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		ref    string
+		want   want
+	}{
+		"ShortForm": {
+			reason: "A reference without a registry should use the explicit Docker Hub registry.",
+			ref:    "acme/foo:v1",
+			want:   want{image: "index.docker.io/acme/foo:v1"},
+		},
+		"ImplicitDockerHub": {
+			reason: "The docker.io registry should be canonicalized to index.docker.io.",
+			ref:    "docker.io/acme/foo:v1",
+			want:   want{image: "index.docker.io/acme/foo:v1"},
+		},
+		"OfficialImage": {
+			reason: "An official Docker Hub image should use the explicit library repository and latest tag.",
+			ref:    "foo",
+			want:   want{image: "index.docker.io/library/foo:latest"},
+		},
+		"OtherRegistry": {
+			reason: "A reference to another registry should keep that registry and use the explicit latest tag.",
+			ref:    "registry.example.org:5000/acme/foo",
+			want:   want{image: "registry.example.org:5000/acme/foo:latest"},
+		},
+		"Digest": {
+			reason: "A digest reference should keep its digest.",
+			ref:    "acme/foo@" + digest,
+			want:   want{image: "index.docker.io/acme/foo@" + digest},
+		},
+		"TagAndDigest": {
+			reason: "A reference with both a tag and a digest should keep only its digest.",
+			ref:    "acme/foo:v1@" + digest,
+			want:   want{image: "index.docker.io/acme/foo@" + digest},
+		},
+		"Invalid": {
+			reason: "An error should be returned if the reference cannot be parsed.",
+			ref:    "Acme/Foo",
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := CanonicalImage(tc.ref)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nCanonicalImage(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.image, got); diff != "" {
+				t.Errorf("\n%s\nCanonicalImage(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestBuildPath(t *testing.T) {
 	type args struct {
 		path string